@@ -0,0 +1,30 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+// embedded ships templates, static files and game data inside the binary
+//
+//go:embed templates static data
+var embedded embed.FS
+
+// assetFS returns the files for one asset directory.
+// An explicitly configured dir wins; dev mode reads the repo checkout; otherwise the embedded copy is used.
+func assetFS(dir, name string, dev bool) fs.FS {
+	switch {
+	case dir != "":
+		return os.DirFS(dir)
+	case dev:
+		return os.DirFS(name)
+	default:
+		sub, err := fs.Sub(embedded, name)
+		if err != nil {
+			// Only possible if the embed directive and name disagree
+			panic(err)
+		}
+		return sub
+	}
+}
@@ -0,0 +1,376 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds all runtime settings.
+// Values are resolved in order: defaults, config file, environment, command-line flags.
+type Config struct {
+	ListenAddr      string        `yaml:"listen_addr"`
+	BaseURL         string        `yaml:"base_url"`
+	BasePath        string        `yaml:"base_path"`
+	DataDir         string        `yaml:"data_dir"`
+	TemplatesDir    string        `yaml:"templates_dir"`
+	StaticDir       string        `yaml:"static_dir"`
+	AdminToken      string        `yaml:"admin_token"`
+	DevMode         bool          `yaml:"dev_mode"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	TLS      TLSConfig      `yaml:"tls"`
+	Log      LogConfig      `yaml:"log"`
+	Snapshot SnapshotConfig `yaml:"snapshot"`
+	Accounts AccountsConfig `yaml:"accounts"`
+	Game     GameConfig     `yaml:"game"`
+}
+
+// TLSConfig enables HTTPS from certificate files or ACME
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// ACME issues certificates automatically for these domains
+	ACMEDomains      []string `yaml:"acme_domains"`
+	ACMEEmail        string   `yaml:"acme_email"`
+	ACMECacheDir     string   `yaml:"acme_cache_dir"`
+	ACMEDirectoryURL string   `yaml:"acme_directory_url"`
+	// ACMECAFile trusts an extra root for the directory (e.g. a local Pebble)
+	ACMECAFile string `yaml:"acme_ca_file"`
+
+	// RedirectAddr serves HTTP->HTTPS redirects (and ACME challenges) when set
+	RedirectAddr string        `yaml:"redirect_addr"`
+	HSTSMaxAge   time.Duration `yaml:"hsts_max_age"`
+	// TrustProxy treats X-Forwarded-Proto: https as a secure request
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+// Enabled reports whether the server terminates TLS itself
+func (t TLSConfig) Enabled() bool {
+	return (t.CertFile != "" && t.KeyFile != "") || t.ACMEEnabled()
+}
+
+// ACMEEnabled reports whether certificates are obtained via ACME
+func (t TLSConfig) ACMEEnabled() bool {
+	return len(t.ACMEDomains) > 0
+}
+
+// LogConfig controls structured logging output
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// SnapshotConfig controls persisting lobbies across restarts
+type SnapshotConfig struct {
+	File     string        `yaml:"file"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// AccountsConfig controls where player accounts and their stats are kept
+type AccountsConfig struct {
+	File string `yaml:"file"`
+}
+
+// GameConfig holds tunable game limits
+type GameConfig struct {
+	MinPlayers     int           `yaml:"min_players"`
+	MaxVoteRounds  int           `yaml:"max_vote_rounds"`
+	SpySelection   string        `yaml:"spy_selection"`
+	Seed           int           `yaml:"seed"`
+	RoomCodeLength int           `yaml:"room_code_length"`
+	SSEBufferSize  int           `yaml:"sse_buffer_size"`
+	SSESendTimeout time.Duration `yaml:"sse_send_timeout"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		ListenAddr:      ":8080",
+		ShutdownTimeout: 10 * time.Second,
+		TLS: TLSConfig{
+			ACMECacheDir: "acme-cache",
+			HSTSMaxAge:   365 * 24 * time.Hour,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Game: GameConfig{
+			MinPlayers:     game.MinPlayers,
+			MaxVoteRounds:  game.MaxVoteRounds,
+			SpySelection:   game.SpySelectionWeighted,
+			RoomCodeLength: game.RoomCodeLength,
+			SSEBufferSize:  game.SSEBufferSize,
+			SSESendTimeout: time.Duration(game.SSETimeoutSeconds) * time.Second,
+		},
+	}
+}
+
+// binding ties one setting to its flag and environment variable
+type binding struct {
+	flag   string
+	env    string
+	usage  string
+	apply  func(c *Config, v string) error
+	isBool bool
+}
+
+func stringSetting(field func(c *Config) *string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func intSetting(field func(c *Config) *int) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolSetting(field func(c *Config) *bool) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("not a boolean: %q", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func listSetting(field func(c *Config) *[]string) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
+
+func durationSetting(field func(c *Config) *time.Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("not a duration: %q", v)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+var bindings = []binding{
+	{"listen", "LISTEN_ADDR", "address to listen on", stringSetting(func(c *Config) *string { return &c.ListenAddr }), false},
+	{"base-url", "BASE_URL", "public base URL used for QR codes and lobby links", stringSetting(func(c *Config) *string { return &c.BaseURL }), false},
+	{"base-path", "BASE_PATH", "URL path prefix the app is mounted at (defaults to the path of the base URL)", stringSetting(func(c *Config) *string { return &c.BasePath }), false},
+	{"dev", "DEV_MODE", "serve assets from the working directory and reload templates on every request", boolSetting(func(c *Config) *bool { return &c.DevMode }), true},
+	{"data-dir", "DATA_DIR", "read places.json and challenges.json from this directory instead of the embedded copy", stringSetting(func(c *Config) *string { return &c.DataDir }), false},
+	{"templates-dir", "TEMPLATES_DIR", "read HTML templates from this directory instead of the embedded copy", stringSetting(func(c *Config) *string { return &c.TemplatesDir }), false},
+	{"static-dir", "STATIC_DIR", "serve static assets from this directory instead of the embedded copy", stringSetting(func(c *Config) *string { return &c.StaticDir }), false},
+	{"admin-token", "ADMIN_TOKEN", "token for the /admin status page (empty disables it)", stringSetting(func(c *Config) *string { return &c.AdminToken }), false},
+	{"shutdown-timeout", "SHUTDOWN_TIMEOUT", "how long to drain connections on shutdown", durationSetting(func(c *Config) *time.Duration { return &c.ShutdownTimeout }), false},
+	{"tls-cert", "TLS_CERT_FILE", "TLS certificate file", stringSetting(func(c *Config) *string { return &c.TLS.CertFile }), false},
+	{"tls-key", "TLS_KEY_FILE", "TLS private key file", stringSetting(func(c *Config) *string { return &c.TLS.KeyFile }), false},
+	{"acme-domains", "ACME_DOMAINS", "comma-separated domains to obtain certificates for via ACME", listSetting(func(c *Config) *[]string { return &c.TLS.ACMEDomains }), false},
+	{"acme-email", "ACME_EMAIL", "contact email for the ACME account", stringSetting(func(c *Config) *string { return &c.TLS.ACMEEmail }), false},
+	{"acme-cache-dir", "ACME_CACHE_DIR", "directory where ACME certificates are cached", stringSetting(func(c *Config) *string { return &c.TLS.ACMECacheDir }), false},
+	{"acme-directory-url", "ACME_DIRECTORY_URL", "ACME directory (empty uses Let's Encrypt)", stringSetting(func(c *Config) *string { return &c.TLS.ACMEDirectoryURL }), false},
+	{"acme-ca-file", "ACME_CA_FILE", "extra root CA to trust when talking to the ACME directory", stringSetting(func(c *Config) *string { return &c.TLS.ACMECAFile }), false},
+	{"redirect-addr", "HTTP_REDIRECT_ADDR", "address for a plain HTTP listener redirecting to HTTPS", stringSetting(func(c *Config) *string { return &c.TLS.RedirectAddr }), false},
+	{"hsts-max-age", "HSTS_MAX_AGE", "Strict-Transport-Security max-age on HTTPS responses (0 disables)", durationSetting(func(c *Config) *time.Duration { return &c.TLS.HSTSMaxAge }), false},
+	{"trust-proxy", "TRUST_PROXY", "trust X-Forwarded-Proto from a reverse proxy", boolSetting(func(c *Config) *bool { return &c.TLS.TrustProxy }), true},
+	{"log-level", "LOG_LEVEL", "minimum log level (debug, info, warn, error)", stringSetting(func(c *Config) *string { return &c.Log.Level }), false},
+	{"log-format", "LOG_FORMAT", "log format (json, text)", stringSetting(func(c *Config) *string { return &c.Log.Format }), false},
+	{"snapshot-file", "SNAPSHOT_FILE", "persist lobbies here on shutdown and restore them on boot", stringSetting(func(c *Config) *string { return &c.Snapshot.File }), false},
+	{"snapshot-interval", "SNAPSHOT_INTERVAL", "also save the snapshot periodically (0 disables)", durationSetting(func(c *Config) *time.Duration { return &c.Snapshot.Interval }), false},
+	{"accounts-file", "ACCOUNTS_FILE", "keep player accounts and stats in this file (empty keeps them in memory)", stringSetting(func(c *Config) *string { return &c.Accounts.File }), false},
+	{"min-players", "MIN_PLAYERS", "minimum players required to start a game", intSetting(func(c *Config) *int { return &c.Game.MinPlayers }), false},
+	{"max-vote-rounds", "MAX_VOTE_ROUNDS", "voting rounds before a tie lets the spy win", intSetting(func(c *Config) *int { return &c.Game.MaxVoteRounds }), false},
+	{"spy-selection", "SPY_SELECTION", "how spies are picked (random, least-recent, weighted)", stringSetting(func(c *Config) *string { return &c.Game.SpySelection }), false},
+	{"game-seed", "GAME_SEED", "seed the game setups so they repeat on every run (0 picks random seeds)", intSetting(func(c *Config) *int { return &c.Game.Seed }), false},
+	{"room-code-length", "ROOM_CODE_LENGTH", "length of generated room codes", intSetting(func(c *Config) *int { return &c.Game.RoomCodeLength }), false},
+	{"sse-buffer-size", "SSE_BUFFER_SIZE", "buffered messages per SSE client", intSetting(func(c *Config) *int { return &c.Game.SSEBufferSize }), false},
+	{"sse-send-timeout", "SSE_SEND_TIMEOUT", "how long to wait on a slow SSE client before dropping a message", durationSetting(func(c *Config) *time.Duration { return &c.Game.SSESendTimeout }), false},
+}
+
+// Load resolves the configuration from defaults, an optional YAML file (-config or CONFIG_FILE),
+// environment variables and command-line args, then validates it
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	fs := flag.NewFlagSet("you-are-officially-sus", flag.ContinueOnError)
+	configPath := fs.String("config", getenv("CONFIG_FILE"), "optional YAML config file")
+
+	// Flags are recorded while parsing and applied last so they win over file and env
+	var flagValues []func(c *Config) error
+	for _, b := range bindings {
+		record := func(v string) error {
+			flagValues = append(flagValues, func(c *Config) error {
+				if err := b.apply(c, v); err != nil {
+					return fmt.Errorf("-%s: %w", b.flag, err)
+				}
+				return nil
+			})
+			return nil
+		}
+		usage := fmt.Sprintf("%s (env %s)", b.usage, b.env)
+		if b.isBool {
+			fs.BoolFunc(b.flag, usage, record)
+		} else {
+			fs.Func(b.flag, usage, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	if *configPath != "" {
+		if err := loadFile(cfg, *configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// DEBUG predates LOG_LEVEL and is kept as a shortcut for it
+	if getenv("DEBUG") != "" && getenv("LOG_LEVEL") == "" {
+		cfg.Log.Level = "debug"
+	}
+	for _, b := range bindings {
+		if v := getenv(b.env); v != "" {
+			if err := b.apply(cfg, v); err != nil {
+				return nil, fmt.Errorf("%s: %w", b.env, err)
+			}
+		}
+	}
+
+	for _, apply := range flagValues {
+		if err := apply(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.BasePath = cfg.resolveBasePath()
+	return cfg, nil
+}
+
+// resolveBasePath normalizes BasePath to "" or "/prefix" without a trailing
+// slash, falling back to the path of BaseURL when no explicit prefix is set
+func (c *Config) resolveBasePath() string {
+	p := c.BasePath
+	if p == "" && c.BaseURL != "" {
+		if u, err := url.Parse(c.BaseURL); err == nil {
+			p = u.Path
+		}
+	}
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// Path prefixes an app-relative path (starting with "/") with the base path
+func (c *Config) Path(p string) string {
+	return c.BasePath + p
+}
+
+// PublicURL returns the absolute URL for an app-relative path, built from BaseURL's
+// scheme and host plus the base path. It returns "" when BaseURL is unset.
+func (c *Config) PublicURL(p string) string {
+	if c.BaseURL == "" {
+		return ""
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + c.Path(p)
+}
+
+// loadFile overlays settings from a YAML file onto cfg
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks that all settings are usable
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.ListenAddr != "", "listen address is required")
+	check(c.ShutdownTimeout > 0, "shutdown timeout must be positive, got %s", c.ShutdownTimeout)
+	check(c.Snapshot.Interval >= 0, "snapshot interval must not be negative, got %s", c.Snapshot.Interval)
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "TLS cert and key files must be set together")
+	check(!(c.TLS.ACMEEnabled() && c.TLS.CertFile != ""), "TLS cert files and ACME domains are mutually exclusive")
+	check(!c.TLS.ACMEEnabled() || c.TLS.ACMECacheDir != "", "ACME cache dir is required when ACME is enabled")
+	check(c.TLS.RedirectAddr == "" || c.TLS.Enabled(), "HTTP redirect listener requires TLS")
+	check(c.TLS.HSTSMaxAge >= 0, "HSTS max-age must not be negative, got %s", c.TLS.HSTSMaxAge)
+	if c.TLS.ACMEDirectoryURL != "" {
+		u, err := url.Parse(c.TLS.ACMEDirectoryURL)
+		check(err == nil && u.Scheme == "https" && u.Host != "", "ACME directory URL must be an absolute https URL, got %q", c.TLS.ACMEDirectoryURL)
+	}
+
+	check(!strings.ContainsAny(c.BasePath, "?#"), "base path must be a plain URL path, got %q", c.BasePath)
+	if c.BaseURL != "" {
+		u, err := url.Parse(c.BaseURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "base URL must be an absolute http(s) URL, got %q", c.BaseURL)
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log level must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		check(false, "log format must be json or text, got %q", c.Log.Format)
+	}
+
+	g := c.Game
+	check(g.MinPlayers >= 3, "min players must be at least 3, got %d", g.MinPlayers)
+	check(g.MaxVoteRounds >= 1, "max vote rounds must be at least 1, got %d", g.MaxVoteRounds)
+	check(slices.Contains(game.SpySelections, g.SpySelection), "spy selection must be random, least-recent or weighted, got %q", g.SpySelection)
+	check(g.RoomCodeLength >= 4 && g.RoomCodeLength <= 12, "room code length must be between 4 and 12, got %d", g.RoomCodeLength)
+	check(g.SSEBufferSize >= 1, "SSE buffer size must be at least 1, got %d", g.SSEBufferSize)
+	check(g.SSESendTimeout > 0, "SSE send timeout must be positive, got %s", g.SSESendTimeout)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a getenv over vars
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+// writeConfig writes a YAML config file and returns its path
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeConfig(t, "listen_addr: \":7000\"\nlog:\n  level: warn\ngame:\n  min_players: 4\n  max_vote_rounds: 5\n")
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(c *Config) bool
+	}{
+		{
+			name: "defaults",
+			want: func(c *Config) bool {
+				return c.ListenAddr == ":8080" && c.Log.Level == "info" && c.Game.MinPlayers == Default().Game.MinPlayers
+			},
+		},
+		{
+			name: "file over defaults",
+			args: []string{"-config", file},
+			want: func(c *Config) bool {
+				return c.ListenAddr == ":7000" && c.Log.Level == "warn" && c.Game.MinPlayers == 4
+			},
+		},
+		{
+			name: "file from CONFIG_FILE",
+			env:  map[string]string{"CONFIG_FILE": file},
+			want: func(c *Config) bool { return c.ListenAddr == ":7000" },
+		},
+		{
+			name: "env over file",
+			args: []string{"-config", file},
+			env:  map[string]string{"LISTEN_ADDR": ":7100", "MIN_PLAYERS": "5"},
+			want: func(c *Config) bool {
+				return c.ListenAddr == ":7100" && c.Game.MinPlayers == 5 && c.Log.Level == "warn"
+			},
+		},
+		{
+			name: "flags over env",
+			args: []string{"-config", file, "-listen", ":7200", "-log-level", "error"},
+			env:  map[string]string{"LISTEN_ADDR": ":7100", "LOG_LEVEL": "debug"},
+			want: func(c *Config) bool {
+				return c.ListenAddr == ":7200" && c.Log.Level == "error" && c.Game.MaxVoteRounds == 5
+			},
+		},
+		{
+			name: "DEBUG without LOG_LEVEL",
+			env:  map[string]string{"DEBUG": "1"},
+			want: func(c *Config) bool { return c.Log.Level == "debug" },
+		},
+		{
+			name: "LOG_LEVEL over DEBUG",
+			env:  map[string]string{"DEBUG": "1", "LOG_LEVEL": "warn"},
+			want: func(c *Config) bool { return c.Log.Level == "warn" },
+		},
+		{
+			name: "bool flag and env",
+			args: []string{"-dev"},
+			env:  map[string]string{"TRUST_PROXY": "true"},
+			want: func(c *Config) bool { return c.DevMode && c.TLS.TrustProxy },
+		},
+	}
+	for _, tt := range tests {
+		cfg, err := Load(tt.args, env(tt.env))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !tt.want(cfg) {
+			t.Errorf("%s: unexpected config %+v", tt.name, cfg)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		yaml string
+		key  string
+	}{
+		{yaml: "listen: \":7000\"\n", key: "listen"},
+		{yaml: "game:\n  min_player: 4\n", key: "min_player"},
+		{yaml: "tls:\n  cert: a.pem\n", key: "cert"},
+	}
+	for _, tt := range tests {
+		_, err := Load([]string{"-config", writeConfig(t, tt.yaml)}, env(nil))
+		if err == nil || !strings.Contains(err.Error(), tt.key) {
+			t.Errorf("%q: error %v, want one naming %q", tt.yaml, err, tt.key)
+		}
+	}
+}
+
+func TestLoadRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{args: []string{"-min-players", "three"}, want: "-min-players: not an integer"},
+		{env: map[string]string{"SHUTDOWN_TIMEOUT": "soon"}, want: "SHUTDOWN_TIMEOUT: not a duration"},
+		{env: map[string]string{"TRUST_PROXY": "maybe"}, want: "TRUST_PROXY: not a boolean"},
+	}
+	for _, tt := range tests {
+		_, err := Load(tt.args, env(tt.env))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v %v: error %v, want %q", tt.args, tt.env, err, tt.want)
+		}
+	}
+}
+
+func TestValidateGameLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(g *GameConfig)
+		want   string
+	}{
+		{"min players", func(g *GameConfig) { g.MinPlayers = 2 }, "min players must be at least 3, got 2"},
+		{"max vote rounds", func(g *GameConfig) { g.MaxVoteRounds = 0 }, "max vote rounds must be at least 1, got 0"},
+		{"spy selection", func(g *GameConfig) { g.SpySelection = "oldest" }, `spy selection must be random, least-recent or weighted, got "oldest"`},
+		{"room code too short", func(g *GameConfig) { g.RoomCodeLength = 3 }, "room code length must be between 4 and 12, got 3"},
+		{"room code too long", func(g *GameConfig) { g.RoomCodeLength = 13 }, "room code length must be between 4 and 12, got 13"},
+		{"SSE buffer size", func(g *GameConfig) { g.SSEBufferSize = 0 }, "SSE buffer size must be at least 1, got 0"},
+		{"SSE send timeout", func(g *GameConfig) { g.SSESendTimeout = 0 }, "SSE send timeout must be positive, got 0s"},
+		{"SSE send timeout negative", func(g *GameConfig) { g.SSESendTimeout = -time.Second }, "SSE send timeout must be positive, got -1s"},
+	}
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("defaults don't validate: %v", err)
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		tt.change(&cfg.Game)
+		err := cfg.Validate()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -1,5 +1,6 @@
 package game
 
+// Defaults for settings that operators can override through the config package
 const (
 	// MinPlayers is the minimum number of players required to start a game
 	MinPlayers = 3
@@ -19,6 +20,9 @@ const (
 	// SSETimeout is the timeout for sending messages to SSE clients
 	SSETimeoutSeconds = 1
 
+	// SeriesBreakSeconds is how long a series game's results stay up before the next game starts
+	SeriesBreakSeconds = 20
+
 	// RoomCodeLength is the length of generated room codes
 	RoomCodeLength = 6
 
@@ -0,0 +1,38 @@
+package game
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+)
+
+// SeedSource hands out the seeds games draw their setup from
+type SeedSource func() int64
+
+// NewSeedSource returns a source of game seeds. With base 0 every seed is
+// random; any other base yields the same sequence of seeds on every run, so a
+// server started with it sets up its games identically.
+func NewSeedSource(base int64) SeedSource {
+	if base == 0 {
+		return func() int64 {
+			var b [8]byte
+			if _, err := crand.Read(b[:]); err != nil {
+				return rand.Int63()
+			}
+			return int64(binary.LittleEndian.Uint64(b[:]) >> 1)
+		}
+	}
+	var mu sync.Mutex
+	seq := rand.New(rand.NewSource(base))
+	return func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return seq.Int63()
+	}
+}
+
+// NewRand returns the generator a game's setup draws from
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
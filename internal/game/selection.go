@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// Spy selection strategies, picked with the spy_selection setting
+const (
+	SpySelectionRandom      = "random"       // every player equally likely, every game
+	SpySelectionLeastRecent = "least-recent" // whoever has gone longest without being a spy
+	SpySelectionWeighted    = "weighted"     // random, but the longer since a player's last spy turn the likelier
+)
+
+// SpySelections lists the valid strategy names
+var SpySelections = []string{SpySelectionRandom, SpySelectionLeastRecent, SpySelectionWeighted}
+
+// PickSpies chooses n of candidates as spies with the named strategy (unknown
+// names fall back to weighted), drawing from rng. history must already count
+// the current game.
+func PickSpies(rng *rand.Rand, strategy string, candidates []string, n int, history *models.LobbyHistory) []string {
+	ids := slices.Clone(candidates)
+	rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	n = min(n, len(ids))
+
+	switch strategy {
+	case SpySelectionRandom:
+		return ids[:n]
+	case SpySelectionLeastRecent:
+		// Stable, so ties stay in shuffled order
+		slices.SortStableFunc(ids, func(a, b string) int {
+			return history.GamesSinceSpy(b) - history.GamesSinceSpy(a)
+		})
+		return ids[:n]
+	default:
+		return pickWeighted(rng, ids, n, history)
+	}
+}
+
+// pickWeighted draws n players without replacement, each weighted by the
+// square of the games since they were last a spy
+func pickWeighted(rng *rand.Rand, ids []string, n int, history *models.LobbyHistory) []string {
+	weights := make([]int, len(ids))
+	total := 0
+	for i, id := range ids {
+		since := max(history.GamesSinceSpy(id), 1)
+		weights[i] = since * since
+		total += weights[i]
+	}
+
+	spies := make([]string, 0, n)
+	for len(spies) < n {
+		r := rng.Intn(total)
+		for i, w := range weights {
+			if r < w {
+				spies = append(spies, ids[i])
+				total -= w
+				weights[i] = 0 // drawn
+				break
+			}
+			r -= w
+		}
+	}
+	return spies
+}
@@ -1,53 +1,48 @@
 package game
 
 import (
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/metrics"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 )
 
-// VoteResult represents the outcome of vote counting
-type VoteResult struct {
-	MostVoted      string
-	IsTie          bool
-	InnocentWon    bool
-	VoteCount      map[string]int
-	VotedCorrectly map[string]bool
-}
-
-// CountVotes analyzes votes and determines the result
-func CountVotes(game *models.Game, players map[string]*models.Player) *VoteResult {
-	voteCount := make(map[string]int)
-	for _, votedFor := range game.Votes {
-		voteCount[votedFor]++
+// CountVotes analyzes votes with the game's voting system and determines the result
+func CountVotes(game *models.Game) *models.VoteResult {
+	if t := game.Trial; t != nil && t.Live() {
+		return CountVerdicts(game)
 	}
+	return Strategy(game.Settings.VotingSystem).Count(game)
+}
 
-	maxVotes := 0
-	var playersWithMaxVotes []string
-	for pID, count := range voteCount {
-		if count > maxVotes {
-			maxVotes = count
-			playersWithMaxVotes = []string{pID}
-		} else if count == maxVotes {
-			playersWithMaxVotes = append(playersWithMaxVotes, pID)
+// CountVerdicts decides a live accusation: only a unanimous guilty verdict
+// convicts the accused, and the guilty verdicts were right if they are a spy
+func CountVerdicts(game *models.Game) *models.VoteResult {
+	t := game.Trial
+	result := &models.VoteResult{VoteCount: map[string]int{}, VotedCorrectly: map[string]bool{}}
+	for voter, guilty := range t.Verdicts {
+		if guilty {
+			result.VoteCount[t.Accused]++
 		}
+		result.VotedCorrectly[voter] = guilty == game.IsSpy(t.Accused)
 	}
-
-	result := &VoteResult{
-		VoteCount: voteCount,
-		IsTie:     len(playersWithMaxVotes) > 1,
+	if t.Guilty() {
+		result.MostVoted, result.InnocentWon = t.Accused, game.IsSpy(t.Accused)
+	} else {
+		result.Acquitted = true
 	}
+	return result
+}
 
-	if !result.IsTie {
-		result.MostVoted = playersWithMaxVotes[0]
-		result.InnocentWon = result.MostVoted == game.SpyID
+// SetStatus moves the game into status, recording how long the previous phase lasted
+func SetStatus(g *models.Game, status models.GameStatus) {
+	if g.Status == status {
+		return
 	}
-
-	// Build voted correctly map
-	result.VotedCorrectly = make(map[string]bool)
-	for voterID, suspectID := range game.Votes {
-		result.VotedCorrectly[voterID] = suspectID == game.SpyID
+	if g.Status != "" && !g.PhaseStartedAt.IsZero() {
+		metrics.PhaseCompleted(string(g.Status), time.Since(g.PhaseStartedAt))
 	}
-
-	return result
+	g.Record(models.GameEvent{Type: models.EventPhaseChanged, Status: status})
 }
 
 // ShouldAdvancePhase determines if a phase should advance based on ready counts
@@ -64,16 +59,7 @@ func ShouldAdvancePhase(readyCount, totalPlayers int, status models.GameStatus)
 
 // GetReadyStateMap returns the appropriate ready state map for the current phase
 func GetReadyStateMap(game *models.Game) map[string]bool {
-	switch game.Status {
-	case models.StatusReadyCheck:
-		return game.ReadyToReveal
-	case models.StatusRoleReveal:
-		return game.ReadyAfterReveal
-	case models.StatusPlaying:
-		return game.ReadyToVote
-	default:
-		return nil
-	}
+	return game.ReadyMap()
 }
 
 // CountReadyPlayers counts how many players are ready in the given map
@@ -9,10 +9,10 @@ import (
 	"github.com/aaronzipp/you-are-officially-sus/internal/store"
 )
 
-// GenerateRoomCode creates a random room code
-func GenerateRoomCode() string {
-	code := make([]byte, RoomCodeLength)
-	for i := range RoomCodeLength {
+// GenerateRoomCode creates a random room code of the given length
+func GenerateRoomCode(length int) string {
+	code := make([]byte, length)
+	for i := range length {
 		n, err := crand.Int(crand.Reader, big.NewInt(int64(len(RoomCodeChars))))
 		if err != nil {
 			// fallback to math/rand if crypto fails
@@ -24,10 +24,10 @@ func GenerateRoomCode() string {
 	return string(code)
 }
 
-// GetUniqueRoomCode generates a unique room code
-func GetUniqueRoomCode(lobbyStore *store.LobbyStore) string {
+// GetUniqueRoomCode generates a unique room code of the given length
+func GetUniqueRoomCode(lobbyStore *store.LobbyStore, length int) string {
 	for {
-		code := GenerateRoomCode()
+		code := GenerateRoomCode(length)
 		if !lobbyStore.Exists(code) {
 			return code
 		}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// VotingStrategy implements one voting system
+type VotingStrategy interface {
+	// Ballot checks that a voter's picks have the shape the system expects and
+	// returns them as a ballot; ok is false if they don't
+	Ballot(picks []string) (ballot models.Ballot, ok bool)
+	// Count tallies the game's current voting round
+	Count(g *models.Game) *models.VoteResult
+}
+
+var strategies = map[models.VotingSystem]VotingStrategy{
+	models.VotingPlurality:  plurality{},
+	models.VotingApproval:   approval{},
+	models.VotingRanked:     ranked{},
+	models.VotingAccusation: accusation{},
+}
+
+// Strategy returns the strategy for system, or plurality for unknown systems
+func Strategy(system models.VotingSystem) VotingStrategy {
+	if s, ok := strategies[system]; ok {
+		return s
+	}
+	return plurality{}
+}
+
+// plurality: one suspect per ballot, the most votes is voted out
+type plurality struct{}
+
+func (plurality) Ballot(picks []string) (models.Ballot, bool) {
+	if len(picks) != 1 || picks[0] == "" {
+		return nil, false
+	}
+	return models.Ballot{picks[0]}, true
+}
+
+func (plurality) Count(g *models.Game) *models.VoteResult {
+	counts := make(map[string]int)
+	for _, ballot := range g.Votes {
+		counts[ballot[0]]++
+	}
+	return decide(g, counts, firstIsSpy(g))
+}
+
+// approval: any number of suspects per ballot, the most approvals is voted out.
+// A ballot is correct if it approves spies only.
+type approval struct{}
+
+func (approval) Ballot(picks []string) (models.Ballot, bool) {
+	return distinct(picks)
+}
+
+func (approval) Count(g *models.Game) *models.VoteResult {
+	counts := make(map[string]int)
+	correct := make(map[string]bool)
+	for voter, ballot := range g.Votes {
+		correct[voter] = true
+		for _, suspect := range ballot {
+			counts[suspect]++
+			correct[voter] = correct[voter] && g.IsSpy(suspect)
+		}
+	}
+	return decide(g, counts, correct)
+}
+
+// ranked: suspects in order of preference (instant runoff). Each ballot counts
+// for its highest suspect still in the running; while nobody has a majority the
+// suspects with the fewest votes drop out. If everyone left is level, it's a tie.
+type ranked struct{}
+
+func (ranked) Ballot(picks []string) (models.Ballot, bool) {
+	// Unused lower ranks are posted empty
+	return distinct(slices.DeleteFunc(slices.Clone(picks), func(p string) bool { return p == "" }))
+}
+
+func (ranked) Count(g *models.Game) *models.VoteResult {
+	out := make(map[string]bool)
+	var eliminated []string
+	for {
+		counts := make(map[string]int)
+		ballots := 0
+		for _, ballot := range g.Votes {
+			if i := slices.IndexFunc(ballot, func(s string) bool { return !out[s] }); i >= 0 {
+				counts[ballot[i]]++
+				ballots++
+			}
+		}
+
+		fewest := ballots
+		for _, n := range counts {
+			fewest = min(fewest, n)
+		}
+		var last []string
+		for suspect, n := range counts {
+			if n*2 > ballots {
+				last = nil
+				break
+			}
+			if n == fewest {
+				last = append(last, suspect)
+			}
+		}
+		if len(last) == 0 || len(last) == len(counts) {
+			// A majority, or nobody left to drop without dropping everyone
+			result := decide(g, counts, firstIsSpy(g))
+			result.Eliminated = eliminated
+			return result
+		}
+		slices.Sort(last)
+		for _, suspect := range last {
+			out[suspect] = true
+		}
+		eliminated = append(eliminated, last...)
+	}
+}
+
+// accusation: nominations like plurality; the most named is put on trial and
+// only a unanimous guilty verdict from the other players votes them out
+type accusation struct{}
+
+func (accusation) Ballot(picks []string) (models.Ballot, bool) {
+	return plurality{}.Ballot(picks)
+}
+
+func (accusation) Count(g *models.Game) *models.VoteResult {
+	result := plurality{}.Count(g)
+	switch t := g.Trial; {
+	case t != nil && t.Guilty():
+		result.IsTie, result.MostVoted, result.InnocentWon = false, t.Accused, g.IsSpy(t.Accused)
+	case t != nil:
+		result.IsTie, result.Acquitted, result.MostVoted, result.InnocentWon = false, true, "", false
+	case !result.IsTie:
+		result.Accused, result.MostVoted, result.InnocentWon = result.MostVoted, "", false
+	}
+	return result
+}
+
+// decide votes out the suspect with the most votes, unless several share it
+func decide(g *models.Game, counts map[string]int, correct map[string]bool) *models.VoteResult {
+	var top []string
+	for suspect, n := range counts {
+		switch {
+		case len(top) == 0 || n > counts[top[0]]:
+			top = []string{suspect}
+		case n == counts[top[0]]:
+			top = append(top, suspect)
+		}
+	}
+	result := &models.VoteResult{VoteCount: counts, VotedCorrectly: correct, IsTie: len(top) != 1}
+	if !result.IsTie {
+		result.MostVoted = top[0]
+		result.InnocentWon = g.IsSpy(top[0])
+	}
+	return result
+}
+
+// firstIsSpy marks the voters whose top suspect is a spy
+func firstIsSpy(g *models.Game) map[string]bool {
+	correct := make(map[string]bool)
+	for voter, ballot := range g.Votes {
+		correct[voter] = g.IsSpy(ballot[0])
+	}
+	return correct
+}
+
+// distinct turns picks into a ballot if it names at least one suspect and none twice
+func distinct(picks []string) (models.Ballot, bool) {
+	if len(picks) == 0 || slices.Contains(picks, "") {
+		return nil, false
+	}
+	seen := make(map[string]bool)
+	for _, p := range picks {
+		if seen[p] {
+			return nil, false
+		}
+		seen[p] = true
+	}
+	return models.Ballot(slices.Clone(picks)), true
+}
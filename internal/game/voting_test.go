@@ -0,0 +1,231 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// votingGame returns a game in its voting round with spy as the only spy
+func votingGame(spy string, votes map[string]models.Ballot) *models.Game {
+	return &models.Game{Spies: map[string]string{spy: spy}, Votes: votes, VoteRound: 1}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		system models.VotingSystem
+		game   *models.Game
+		want   models.VoteResult
+	}{
+		{
+			name:   "plurality voting out the spy",
+			system: models.VotingPlurality,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"x"}, "c": {"y"}}),
+			want: models.VoteResult{
+				MostVoted: "x", InnocentWon: true,
+				VoteCount:      map[string]int{"x": 2, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false},
+			},
+		},
+		{
+			name:   "plurality voting out an innocent",
+			system: models.VotingPlurality,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"y"}, "b": {"y"}, "x": {"y"}}),
+			want: models.VoteResult{
+				MostVoted:      "y",
+				VoteCount:      map[string]int{"y": 3},
+				VotedCorrectly: map[string]bool{"a": false, "b": false, "x": false},
+			},
+		},
+		{
+			name:   "plurality tie",
+			system: models.VotingPlurality,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"y"}}),
+			want: models.VoteResult{
+				IsTie:          true,
+				VoteCount:      map[string]int{"x": 1, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": false},
+			},
+		},
+		{
+			name:   "plurality revote counts only the current round",
+			system: models.VotingPlurality,
+			game: &models.Game{
+				Spies:     map[string]string{"x": "x"},
+				PastVotes: []map[string]models.Ballot{{"a": {"x"}, "b": {"x"}, "c": {"y"}}},
+				Votes:     map[string]models.Ballot{"a": {"y"}, "b": {"y"}, "c": {"y"}},
+				VoteRound: 2,
+			},
+			want: models.VoteResult{
+				MostVoted:      "y",
+				VoteCount:      map[string]int{"y": 3},
+				VotedCorrectly: map[string]bool{"a": false, "b": false, "c": false},
+			},
+		},
+		{
+			name:   "approval voting out the most approved",
+			system: models.VotingApproval,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x", "y"}, "b": {"x"}, "c": {"z"}}),
+			want: models.VoteResult{
+				MostVoted: "x", InnocentWon: true,
+				VoteCount:      map[string]int{"x": 2, "y": 1, "z": 1},
+				VotedCorrectly: map[string]bool{"a": false, "b": true, "c": false},
+			},
+		},
+		{
+			name:   "approval tie",
+			system: models.VotingApproval,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x", "y"}, "b": {"x"}, "c": {"y"}}),
+			want: models.VoteResult{
+				IsTie:          true,
+				VoteCount:      map[string]int{"x": 2, "y": 2},
+				VotedCorrectly: map[string]bool{"a": false, "b": true, "c": false},
+			},
+		},
+		{
+			name:   "ranked majority of first choices",
+			system: models.VotingRanked,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x", "y"}, "b": {"x"}, "c": {"y", "x"}}),
+			want: models.VoteResult{
+				MostVoted: "x", InnocentWon: true,
+				VoteCount:      map[string]int{"x": 2, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false},
+			},
+		},
+		{
+			name:   "ranked runoff moves the eliminated suspect's ballots on",
+			system: models.VotingRanked,
+			game: votingGame("y", map[string]models.Ballot{
+				"a": {"x", "z"}, "b": {"x"}, "c": {"y", "x"}, "d": {"y"}, "e": {"z", "y"},
+			}),
+			want: models.VoteResult{
+				MostVoted: "y", InnocentWon: true,
+				VoteCount:      map[string]int{"x": 2, "y": 3},
+				VotedCorrectly: map[string]bool{"a": false, "b": false, "c": true, "d": true, "e": false},
+				Eliminated:     []string{"z"},
+			},
+		},
+		{
+			name:   "ranked drops everyone level last together and ends level",
+			system: models.VotingRanked,
+			game: votingGame("x", map[string]models.Ballot{
+				"a": {"x"}, "b": {"x"}, "c": {"y"}, "d": {"y"}, "e": {"z", "x"}, "f": {"w", "y"},
+			}),
+			want: models.VoteResult{
+				IsTie:          true,
+				VoteCount:      map[string]int{"x": 3, "y": 3},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false, "d": false, "e": false, "f": false},
+				Eliminated:     []string{"w", "z"},
+			},
+		},
+		{
+			name:   "ranked majority of the ballots left after some are exhausted",
+			system: models.VotingRanked,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"x"}, "c": {"y"}, "d": {"z"}}),
+			want: models.VoteResult{
+				MostVoted: "x", InnocentWon: true,
+				VoteCount:      map[string]int{"x": 2},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false, "d": false},
+				Eliminated:     []string{"y", "z"},
+			},
+		},
+		{
+			name:   "ranked tie when everyone is level from the start",
+			system: models.VotingRanked,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x", "y"}, "b": {"y", "x"}}),
+			want: models.VoteResult{
+				IsTie:          true,
+				VoteCount:      map[string]int{"x": 1, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": false},
+			},
+		},
+		{
+			name:   "accusation puts the most named on trial",
+			system: models.VotingAccusation,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"x"}, "c": {"y"}}),
+			want: models.VoteResult{
+				Accused:        "x",
+				VoteCount:      map[string]int{"x": 2, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false},
+			},
+		},
+		{
+			name:   "accusation tie puts nobody on trial",
+			system: models.VotingAccusation,
+			game:   votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"y"}}),
+			want: models.VoteResult{
+				IsTie:          true,
+				VoteCount:      map[string]int{"x": 1, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": false},
+			},
+		},
+		{
+			name:   "accusation unanimous guilty verdict votes out the accused",
+			system: models.VotingAccusation,
+			game: func() *models.Game {
+				g := votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"x"}, "c": {"y"}})
+				g.Trial = &models.Trial{Accused: "x", Verdicts: map[string]bool{"a": true, "b": true, "c": true}}
+				return g
+			}(),
+			want: models.VoteResult{
+				MostVoted: "x", InnocentWon: true,
+				VoteCount:      map[string]int{"x": 2, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false},
+			},
+		},
+		{
+			name:   "accusation acquits on a single not guilty verdict",
+			system: models.VotingAccusation,
+			game: func() *models.Game {
+				g := votingGame("x", map[string]models.Ballot{"a": {"x"}, "b": {"x"}, "c": {"y"}})
+				g.Trial = &models.Trial{Accused: "x", Verdicts: map[string]bool{"a": true, "b": true, "c": false}}
+				return g
+			}(),
+			want: models.VoteResult{
+				Acquitted:      true,
+				VoteCount:      map[string]int{"x": 2, "y": 1},
+				VotedCorrectly: map[string]bool{"a": true, "b": true, "c": false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Strategy(tt.system).Count(tt.game)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Count() = %+v, want %+v", *got, tt.want)
+			}
+			if undecided := tt.want.IsTie || tt.want.Acquitted; got.Undecided() != undecided {
+				t.Errorf("Undecided() = %v, want %v", got.Undecided(), undecided)
+			}
+		})
+	}
+}
+
+func TestBallot(t *testing.T) {
+	tests := []struct {
+		system models.VotingSystem
+		picks  []string
+		want   models.Ballot
+	}{
+		{models.VotingPlurality, []string{"x"}, models.Ballot{"x"}},
+		{models.VotingPlurality, []string{"x", "y"}, nil},
+		{models.VotingPlurality, []string{""}, nil},
+		{models.VotingApproval, []string{"x", "y"}, models.Ballot{"x", "y"}},
+		{models.VotingApproval, []string{"x", "x"}, nil},
+		{models.VotingApproval, nil, nil},
+		{models.VotingRanked, []string{"y", "x", ""}, models.Ballot{"y", "x"}},
+		{models.VotingRanked, []string{"", ""}, nil},
+		{models.VotingAccusation, []string{"x"}, models.Ballot{"x"}},
+		{"unknown", []string{"x"}, models.Ballot{"x"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := Strategy(tt.system).Ballot(tt.picks)
+		if ok != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s Ballot(%q) = %v, %v; want %v", tt.system, tt.picks, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// accountCookieName holds the account sign-in token
+const accountCookieName = "account_token"
+
+// accountCookieMaxAge keeps the browser signed in for a year
+const accountCookieMaxAge = 365 * 24 * time.Hour
+
+// favoriteLocationCount is how many favorite locations the stats page lists
+const favoriteLocationCount = 5
+
+// accountToken returns the sign-in token from the account cookie, or "" if there is none
+func accountToken(r *http.Request) string {
+	cookie, err := r.Cookie(accountCookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// accountID returns the ID of the account the request is signed in to, or ""
+func (ctx *Context) accountID(r *http.Request) string {
+	account, ok := ctx.Accounts.ByToken(accountToken(r))
+	if !ok {
+		return ""
+	}
+	return account.ID
+}
+
+// setAccountCookie signs the browser in with token; an empty token signs it out
+func (ctx *Context) setAccountCookie(w http.ResponseWriter, r *http.Request, token string) {
+	maxAge := int(accountCookieMaxAge.Seconds())
+	if token == "" {
+		maxAge = -1
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     accountCookieName,
+		Value:    token,
+		Path:     ctx.Config.Path("/"),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   ctx.isSecureRequest(r),
+	})
+}
+
+// recordResults adds a finished game's results to the players' account stats.
+// Stats are best effort, so a failed save is only logged.
+func (ctx *Context) recordResults(logger *slog.Logger, results map[string]models.GameResult) {
+	if err := ctx.Accounts.RecordResults(results); err != nil {
+		logger.Error("Failed to record account stats", "error", err)
+	}
+}
+
+// HandleStats shows the signed-in account's stats across all lobbies,
+// or the form to create an account
+func (ctx *Context) HandleStats(w http.ResponseWriter, r *http.Request) {
+	token := accountToken(r)
+	account, signedIn := ctx.Accounts.ByToken(token)
+
+	// The link carries the raw token, so whoever has it can sign in as this
+	// account. It's only ever shown to the account holder.
+	signInPath := "/stats/sign-in?token=" + token
+	signInLink := ctx.Config.PublicURL(signInPath)
+	if signInLink == "" {
+		signInLink = ctx.Config.Path(signInPath)
+	}
+
+	ctx.render(w, r, "stats.html", struct {
+		SignedIn          bool
+		Account           models.Account
+		FavoriteLocations []models.LocationCount
+		SignInLink        string
+	}{
+		SignedIn:          signedIn,
+		Account:           account,
+		FavoriteLocations: account.Stats.FavoriteLocations(favoriteLocationCount),
+		SignInLink:        signInLink,
+	})
+}
+
+// HandleCreateAccount creates an account and signs the browser in to it
+func (ctx *Context) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
+	nickname := strings.TrimSpace(r.FormValue("nickname"))
+	if nickname == "" {
+		ctx.Error(w, r, ctx.T(r, "error.name_required"), http.StatusBadRequest)
+		return
+	}
+
+	account, token, err := ctx.Accounts.Create(nickname)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to create account", "error", err)
+		ctx.Error(w, r, ctx.T(r, "error.internal"), http.StatusInternalServerError)
+		return
+	}
+	logging.FromContext(r.Context()).Info("Account created", "account", account.ID)
+
+	ctx.setAccountCookie(w, r, token)
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/stats"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleAccountSignIn signs the browser in from a magic link carrying the token
+func (ctx *Context) HandleAccountSignIn(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if _, ok := ctx.Accounts.ByToken(token); !ok {
+		ctx.Error(w, r, ctx.T(r, "error.account_not_found"), http.StatusNotFound)
+		return
+	}
+	ctx.setAccountCookie(w, r, token)
+	http.Redirect(w, r, ctx.Config.Path("/stats"), http.StatusSeeOther)
+}
+
+// HandleAccountSignOut forgets the account in this browser. The account itself
+// stays and the sign-in link keeps working.
+func (ctx *Context) HandleAccountSignOut(w http.ResponseWriter, r *http.Request) {
+	ctx.setAccountCookie(w, r, "")
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/stats"))
+	w.WriteHeader(http.StatusOK)
+}
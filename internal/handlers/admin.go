@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// adminCookieName stores the admin token after a successful ?token= login
+const adminCookieName = "admin_token"
+
+// HandleHealthz reports that the process is alive
+func (ctx *Context) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// HandleReadyz reports whether the server should receive traffic.
+// It fails while draining for shutdown or when game content failed to load.
+func (ctx *Context) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if reason := ctx.notReadyReason(); reason != "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(reason + "\n"))
+		return
+	}
+	w.Write([]byte("ready\n"))
+}
+
+// notReadyReason returns why the server isn't ready, or "" if it is
+func (ctx *Context) notReadyReason() string {
+	switch {
+	case ctx.Draining():
+		return "shutting down"
+	case ctx.Templates == nil:
+		return "templates not loaded"
+	case len(ctx.locations(i18n.DefaultLanguage)) == 0:
+		return "no locations loaded"
+	case len(ctx.challenges(i18n.DefaultLanguage)) == 0:
+		return "no challenges loaded"
+	default:
+		return ""
+	}
+}
+
+type adminSSEClient struct {
+	PlayerID    string    `json:"player_id"`
+	PlayerName  string    `json:"player_name"`
+	ConnectedAt time.Time `json:"connected_at"`
+	Age         string    `json:"age"`
+}
+
+type adminLobby struct {
+	Code        string           `json:"code"`
+	HostID      string           `json:"host_id"`
+	HostName    string           `json:"host_name"`
+	PlayerCount int              `json:"player_count"`
+	Phase       string           `json:"phase"`
+	GameMode    string           `json:"game_mode,omitempty"`
+	Seed        int64            `json:"seed,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	SSEClients  []adminSSEClient `json:"sse_clients"`
+}
+
+type adminStatus struct {
+	Ready      bool         `json:"ready"`
+	NotReady   string       `json:"not_ready_reason,omitempty"`
+	Lobbies    []adminLobby `json:"lobbies"`
+	Players    int          `json:"players"`
+	SSEClients int          `json:"sse_clients"`
+	Locations  int          `json:"locations"`
+	Challenges int          `json:"challenges"`
+}
+
+// HandleAdmin shows server status as HTML, or JSON when requested via ?format=json or Accept
+func (ctx *Context) HandleAdmin(w http.ResponseWriter, r *http.Request) {
+	wantJSON := r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+
+	// Exchange a browser ?token= login for a cookie so the token doesn't linger in the URL
+	if r.URL.Query().Get("token") != "" && !wantJSON {
+		http.SetCookie(w, &http.Cookie{
+			Name:     adminCookieName,
+			Value:    ctx.Config.AdminToken,
+			Path:     ctx.Config.Path("/admin"),
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+			Secure:   ctx.isSecureRequest(r),
+		})
+		http.Redirect(w, r, ctx.Config.Path("/admin"), http.StatusSeeOther)
+		return
+	}
+
+	status := ctx.buildAdminStatus()
+	if wantJSON {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(status)
+		return
+	}
+
+	ctx.render(w, r, "admin.html", status)
+}
+
+// HandleAdminCloseLobby force-closes a lobby through the same path as HandleCloseLobby
+func (ctx *Context) HandleAdminCloseLobby(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+	logging.FromContext(r.Context()).Warn("Admin force-closed lobby")
+	ctx.closeLobby(lobby)
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", ctx.Config.Path("/admin"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, ctx.Config.Path("/admin"), http.StatusSeeOther)
+}
+
+// requireAdmin checks the admin token from the Authorization header, ?token= or the admin cookie.
+// The admin area is hidden (404) unless an admin token is configured.
+func (ctx *Context) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if ctx.Config.AdminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	token := ""
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		if cookie, err := r.Cookie(adminCookieName); err == nil {
+			token = cookie.Value
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(ctx.Config.AdminToken)) != 1 {
+		logging.FromContext(r.Context()).Warn("Rejected admin request")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// buildAdminStatus collects a point-in-time view of all lobbies
+func (ctx *Context) buildAdminStatus() adminStatus {
+	reason := ctx.notReadyReason()
+	status := adminStatus{
+		Ready:      reason == "",
+		NotReady:   reason,
+		Lobbies:    make([]adminLobby, 0),
+		Locations:  len(ctx.locations(i18n.DefaultLanguage)),
+		Challenges: len(ctx.challenges(i18n.DefaultLanguage)),
+	}
+
+	now := time.Now()
+	for _, lobby := range ctx.LobbyStore.All() {
+		lobby.RLock()
+		info := adminLobby{
+			Code:        lobby.Code,
+			HostID:      lobby.Host,
+			PlayerCount: len(lobby.Players),
+			Phase:       string(models.StatusWaiting),
+			CreatedAt:   lobby.CreatedAt,
+			SSEClients:  make([]adminSSEClient, 0),
+		}
+		if host, ok := lobby.Players[lobby.Host]; ok {
+			info.HostName = host.Name
+		}
+		if g := lobby.CurrentGame; g != nil {
+			info.Phase = string(g.Status)
+			info.GameMode = string(g.Mode)
+			if g.Setup != nil {
+				info.Seed = g.Seed
+			}
+		}
+		for _, c := range lobby.SSEClientInfos() {
+			client := adminSSEClient{
+				PlayerID:    c.PlayerID,
+				ConnectedAt: c.ConnectedAt,
+				Age:         now.Sub(c.ConnectedAt).Round(time.Second).String(),
+			}
+			if p, ok := lobby.Players[c.PlayerID]; ok {
+				client.PlayerName = p.Name
+			}
+			info.SSEClients = append(info.SSEClients, client)
+		}
+		lobby.RUnlock()
+
+		sort.Slice(info.SSEClients, func(i, j int) bool {
+			return info.SSEClients[i].ConnectedAt.Before(info.SSEClients[j].ConnectedAt)
+		})
+		status.Players += info.PlayerCount
+		status.SSEClients += len(info.SSEClients)
+		status.Lobbies = append(status.Lobbies, info)
+	}
+
+	sort.Slice(status.Lobbies, func(i, j int) bool { return status.Lobbies[i].Code < status.Lobbies[j].Code })
+	return status
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+)
+
+// errorTarget is the element every page provides for HTMX error fragments
+const errorTarget = "#error-message-display"
+
+// Error reports a failure to the user. HTMX requests get the error_message.html
+// fragment retargeted at #error-message-display (htmx only swaps 2xx responses,
+// so it is sent as 200); page loads get the styled error.html page with status.
+func (ctx *Context) Error(w http.ResponseWriter, r *http.Request, message string, status int) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("HX-Retarget", errorTarget)
+		w.Header().Set("HX-Reswap", "innerHTML")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(ctx.ErrorMessage(ctx.lang(r), message)))
+		return
+	}
+
+	title, ok := ctx.I18n.Lookup(ctx.lang(r), "status."+strconv.Itoa(status))
+	if !ok {
+		title = http.StatusText(status)
+	}
+
+	var buf bytes.Buffer
+	err := ctx.Templates.ExecuteTemplate(&buf, ctx.lang(r), "error.html", struct {
+		Status  int
+		Title   string
+		Message string
+	}{
+		Status:  status,
+		Title:   title,
+		Message: message,
+	})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to render error page", "error", err)
+		http.Error(w, message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// HandleNotFound serves the 404 page for unknown paths
+func (ctx *Context) HandleNotFound(w http.ResponseWriter, r *http.Request) {
+	ctx.Error(w, r, ctx.T(r, "error.not_found"), http.StatusNotFound)
+}
+
+// render executes a page template into a buffer first so a template error
+// becomes an error page instead of a half-written response
+func (ctx *Context) render(w http.ResponseWriter, r *http.Request, name string, data any) {
+	var buf bytes.Buffer
+	if err := ctx.Templates.ExecuteTemplate(&buf, ctx.lang(r), name, data); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to render page", "template", name, "error", err)
+		ctx.Error(w, r, ctx.T(r, "error.render"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// Recover turns a panicking handler into an error page (or HTMX fragment) and logs the stack
+func (ctx *Context) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			logging.FromContext(r.Context()).Error("Handler panicked", "panic", err, "stack", string(debug.Stack()))
+			if tw.wrote {
+				// Too late for an error page; drop the connection like net/http would
+				panic(http.ErrAbortHandler)
+			}
+			ctx.Error(w, r, ctx.T(r, "error.internal"), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// trackingWriter records whether a response has started
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (tw *trackingWriter) WriteHeader(code int) {
+	tw.wrote = true
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *trackingWriter) Write(b []byte) (int, error) {
+	tw.wrote = true
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush keeps SSE streaming working through the wrapper
+func (tw *trackingWriter) Flush() {
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		tw.wrote = true
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (tw *trackingWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
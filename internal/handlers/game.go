@@ -1,107 +1,83 @@
 package handlers
 
 import (
-	"log"
+	"maps"
 	"math/rand"
 	"net/http"
-	"os"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 	"github.com/aaronzipp/you-are-officially-sus/internal/render"
 	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
 )
 
-var debug bool
-
-func init() {
-	debug = os.Getenv("DEBUG") != ""
-}
-
-// HandleGameMux routes game subpaths by phase and actions
-func (ctx *Context) HandleGameMux(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/game/")
-	parts := strings.Split(strings.Trim(path, "/"), "/")
-	if len(parts) == 0 || parts[0] == "" {
-		http.Error(w, "Invalid URL", http.StatusBadRequest)
-		return
-	}
-	roomCode := parts[0]
-	seg := ""
-	if len(parts) > 1 {
-		seg = parts[1]
-	}
-
-// Reject unknown subpaths under /game/:code
-if seg != "" && seg != "confirm-reveal" && seg != "roles" && seg != "play" && seg != "voting" && seg != "word-collection" && seg != "ready" && seg != "vote" && seg != "submit-word" && seg != "redirect" {
-http.NotFound(w, r)
-return
-}
-
-	// Redirect helper for HTMX
-	if seg == "redirect" {
-		to := r.URL.Query().Get("to")
-		if to == "" {
-			to = "/lobby/" + roomCode
-		} else if !strings.HasPrefix(to, "/") {
-			to = "/game/" + roomCode + "/" + to
-		}
-		w.Header().Set("HX-Location", to)
-		w.WriteHeader(http.StatusOK)
-		return
+// HandleGameRedirect is the HTMX navigation helper used by redirect snippets:
+// it answers with HX-Location for ?to= (app-relative, or a phase page of this game)
+func (ctx *Context) HandleGameRedirect(w http.ResponseWriter, r *http.Request) {
+	roomCode := r.PathValue("code")
+	to := r.URL.Query().Get("to")
+	if to == "" {
+		to = "/lobby/" + roomCode
+	} else if !strings.HasPrefix(to, "/") {
+		to = "/game/" + roomCode + "/" + to
 	}
-
-// POST actions under /game/:code
-if r.Method == http.MethodPost {
-switch seg {
-case "ready":
-ctx.gameHandleReadyCookie(w, r, roomCode)
-return
-case "vote":
-ctx.gameHandleVoteCookie(w, r, roomCode)
-return
-case "submit-word":
-ctx.gameHandleSubmitWord(w, r, roomCode)
-return
-default:
-http.Error(w, "Not found", http.StatusNotFound)
-return
-}
+	w.Header().Set("HX-Location", ctx.Config.Path(to))
+	w.WriteHeader(http.StatusOK)
 }
 
-	// GET phase pages: confirm-reveal, roles, play, voting
-	lobby, playerID, err := ctx.getLobbyAndPlayer(r, roomCode)
-	if err != nil {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
+// HandleGamePage renders the page for the current game phase,
+// redirecting to the canonical phase path if the URL is stale
+func (ctx *Context) HandleGamePage(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
 
 	lobby.RLock()
 	g := lobby.CurrentGame
+	var status models.GameStatus
+	if g != nil {
+		status = g.Status
+	}
 	lobby.RUnlock()
 	if g == nil {
-		http.Redirect(w, r, "/lobby/"+roomCode, http.StatusSeeOther)
+		http.Redirect(w, r, ctx.Config.Path("/lobby/"+roomCode), http.StatusSeeOther)
 		return
 	}
 
 	// Guard: ensure path matches current phase; redirect canonical path
-	currentPath := game.PhasePathFor(roomCode, g.Status)
-	if seg == "" || !strings.HasSuffix(currentPath, "/"+seg) {
+	currentPath := game.PhasePathFor(roomCode, status)
+	if r.URL.Path != currentPath {
 		if r.Header.Get("HX-Request") == "true" {
-			w.Header().Set("HX-Redirect", currentPath)
+			w.Header().Set("HX-Redirect", ctx.Config.Path(currentPath))
 			w.WriteHeader(http.StatusOK)
 		} else {
-			http.Redirect(w, r, currentPath, http.StatusSeeOther)
+			http.Redirect(w, r, ctx.Config.Path(currentPath), http.StatusSeeOther)
 		}
 		return
 	}
 
+	// Word collection happens before roles are assigned
+	if status == models.StatusWordCollection {
+		ctx.handleWordCollectionPage(w, r, lobby, playerID, roomCode)
+		return
+	}
+
 	// Build page using per-phase template
 	lobby.RLock()
 	g = lobby.CurrentGame
+	if g == nil {
+		lobby.RUnlock()
+		http.Redirect(w, r, ctx.Config.Path("/lobby/"+roomCode), http.StatusSeeOther)
+		return
+	}
 	playerInfo := g.PlayerInfo[playerID]
+	if playerInfo == nil {
+		lobby.RUnlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_in_round"), http.StatusConflict)
+		return
+	}
 
 	isReady := false
 	switch g.Status {
@@ -125,9 +101,24 @@ return
 		IsReady         bool
 		HasVoted        bool
 		VoteRound       int
+		VotingSystem    models.VotingSystem
+		Suspects        []*models.Player // everyone but the player
+		Accused         string           // name of the player on trial
+		Accuser         string           // name of the player who stopped play to accuse, if they did
+		IsAccused       bool
+		HasVerdict      bool
+		ChangePath      string // where a cast vote or verdict can be changed; empty if it can't
+		Changing        bool   // the player came back to change their vote
+		AccusationsLeft int
+		GuessOptions    []string // the locations a spy can guess while playing
 		FirstQuestioner string
 		PlayStartedAt   int64 // Unix timestamp for client-side timer sync
+		PausedAt        int64 // Unix timestamp the clock stopped at, 0 while it runs
+		DurationSeconds int   // length of the playing phase
+		SpyCount        int
 		IsHost          bool
+		CanHost         bool // host or co-host
+		MinPlayers      int
 	}{
 		RoomCode:        roomCode,
 		PlayerID:        playerID,
@@ -138,54 +129,69 @@ return
 		Challenge:       playerInfo.Challenge,
 		IsSpy:           playerInfo.IsSpy,
 		IsReady:         isReady,
-		HasVoted:        g.Votes[playerID] != "",
+		HasVoted:        len(g.Votes[playerID]) > 0,
 		VoteRound:       g.VoteRound,
+		VotingSystem:    g.Settings.VotingSystem,
 		FirstQuestioner: g.FirstQuestioner,
+		AccusationsLeft: g.Settings.Accusations - g.Accusations[playerID],
 		PlayStartedAt:   g.PlayStartedAt.Unix(),
+		DurationSeconds: int(g.Settings.DiscussionTime().Seconds()),
+		SpyCount:        len(g.Spies),
 		IsHost:          lobby.Host == playerID,
+		CanHost:         lobby.CanHost(playerID),
+		MinPlayers:      ctx.Config.Game.MinPlayers,
+	}
+	for _, p := range data.Players {
+		if p.ID != playerID {
+			data.Suspects = append(data.Suspects, p)
+		}
+	}
+	if t := g.Trial; t != nil {
+		if p, ok := lobby.Players[t.Accused]; ok {
+			data.Accused = p.Name
+		}
+		if p, ok := lobby.Players[t.Accuser]; ok {
+			data.Accuser = p.Name
+		}
+		data.IsAccused = t.Accused == playerID
+		_, data.HasVerdict = t.Verdicts[playerID]
+	}
+	if !g.PausedAt.IsZero() {
+		data.PausedAt = g.PausedAt.Unix()
+	}
+	if playerInfo.IsSpy && g.Status == models.StatusPlaying {
+		data.GuessOptions = ctx.guessOptions(lobby, g)
+	}
+	if canChangeVote(g, playerID) {
+		data.ChangePath = currentPath
+		data.Changing = r.URL.Query().Get("change") != ""
 	}
 	lobby.RUnlock()
 
-// Handle word collection phase separately
-if g.Status == models.StatusWordCollection {
-ctx.handleWordCollectionPage(w, r, lobby, playerID, roomCode)
-return
-}
-
-// Select template by phase
-tmpl := ""
-switch g.Status {
-case models.StatusReadyCheck:
-tmpl = "game_confirm_reveal.html"
-case models.StatusRoleReveal:
-tmpl = "game_roles.html"
-case models.StatusPlaying:
-tmpl = "game_play.html"
-case models.StatusVoting:
-tmpl = "game_voting.html"
-default:
-// Should not happen due to guard; send to lobby
-w.Header().Set("HX-Redirect", "/lobby/"+roomCode)
-w.WriteHeader(http.StatusOK)
-return
-}
-ctx.Templates.ExecuteTemplate(w, tmpl, data)
-}
-
-// gameHandleReadyCookie updates readiness using cookie-based player ID
-func (ctx *Context) gameHandleReadyCookie(w http.ResponseWriter, r *http.Request, roomCode string) {
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
+	// Select template by phase, as it was when the page data was read
+	tmpl := ""
+	switch data.Status {
+	case models.StatusReadyCheck:
+		tmpl = "game_confirm_reveal.html"
+	case models.StatusRoleReveal:
+		tmpl = "game_roles.html"
+	case models.StatusPlaying:
+		tmpl = "game_play.html"
+	case models.StatusVoting:
+		tmpl = "game_voting.html"
+	default:
+		// Should not happen due to guard; send to lobby
+		w.Header().Set("HX-Redirect", ctx.Config.Path("/lobby/"+roomCode))
+		w.WriteHeader(http.StatusOK)
 		return
 	}
+	ctx.render(w, r, tmpl, data)
+}
 
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-	playerID := cookie.Value
+// HandleReady toggles the player's readiness for the current phase
+func (ctx *Context) HandleReady(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
 
 	// Values derived from server state only
 	var readyCountMsg string
@@ -197,37 +203,27 @@ func (ctx *Context) gameHandleReadyCookie(w http.ResponseWriter, r *http.Request
 	g := lobby.CurrentGame
 	if g == nil {
 		lobby.Unlock()
-		http.Error(w, "No game in progress", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.no_game"), http.StatusBadRequest)
 		return
 	}
 
 	statusBefore := g.Status
+	if statusBefore == models.StatusPlaying && g.Trial != nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.trial_running"), http.StatusBadRequest)
+		return
+	}
 
 	// Update readiness per phase rules (toggle in all phases to surface issues)
-	var isReady bool
-	var prev bool
-	var readyStateMap map[string]bool
-	switch statusBefore {
-	case models.StatusReadyCheck:
-		readyStateMap = g.ReadyToReveal
-		prev = g.ReadyToReveal[playerID]
-		g.ReadyToReveal[playerID] = !g.ReadyToReveal[playerID]
-		isReady = g.ReadyToReveal[playerID]
-	case models.StatusRoleReveal:
-		readyStateMap = g.ReadyAfterReveal
-		prev = g.ReadyAfterReveal[playerID]
-		g.ReadyAfterReveal[playerID] = !g.ReadyAfterReveal[playerID]
-		isReady = g.ReadyAfterReveal[playerID]
-	case models.StatusPlaying:
-		readyStateMap = g.ReadyToVote
-		prev = g.ReadyToVote[playerID]
-		g.ReadyToVote[playerID] = !g.ReadyToVote[playerID]
-		isReady = g.ReadyToVote[playerID]
-	default:
+	readyStateMap := game.GetReadyStateMap(g)
+	if readyStateMap == nil {
 		lobby.Unlock()
-		http.Error(w, "Invalid game phase", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.invalid_phase"), http.StatusBadRequest)
 		return
 	}
+	prev := readyStateMap[playerID]
+	g.Record(models.GameEvent{Type: models.EventReadyToggled, Player: playerID, Ready: !prev})
+	isReady := readyStateMap[playerID]
 
 	// Compute ready count from server state (no client math) and gather confirmed names using lobby players
 	readyCount := 0
@@ -262,97 +258,71 @@ func (ctx *Context) gameHandleReadyCookie(w http.ResponseWriter, r *http.Request
 	// Prepare outgoing UI for the CURRENT (pre-advance) phase
 	switch statusBefore {
 	case models.StatusReadyCheck:
-		readyCountMsg = ctx.ReadyCount(readyCount, len(lobby.Players), "players ready")
+		readyCountMsg = ctx.ReadyCount(lobby.Language, readyCount, len(lobby.Players), "count.ready")
 		readyCountEventName = "ready-count-check"
 	case models.StatusRoleReveal:
-		readyCountMsg = ctx.ReadyCount(readyCount, len(lobby.Players), "players ready")
+		readyCountMsg = ctx.ReadyCount(lobby.Language, readyCount, len(lobby.Players), "count.ready")
 		readyCountEventName = "ready-count-reveal"
 	case models.StatusPlaying:
-		readyCountMsg = ctx.ReadyCount(readyCount, len(lobby.Players), "players ready to vote")
+		readyCountMsg = ctx.ReadyCount(lobby.Language, readyCount, len(lobby.Players), "count.ready_to_vote")
 		readyCountEventName = "ready-count-playing"
 	}
 
 	buttonID := "ready-button-check"
-	buttonText := "I'm Ready to See My Role"
+	buttonText := "ready.check"
 	buttonClass := "btn btn-primary"
 	switch statusBefore {
 	case models.StatusReadyCheck:
 		buttonID = "ready-button-check"
 		if isReady {
-			buttonText = "✓ Ready - Waiting for others..."
+			buttonText = "ready.check_done"
 			buttonClass = "btn btn-success"
 		} else {
-			buttonText = "I'm Ready to See My Role"
+			buttonText = "ready.check"
 			buttonClass = "btn btn-primary"
 		}
 	case models.StatusRoleReveal:
 		buttonID = "ready-button-role"
 		if isReady {
-			buttonText = "✓ Waiting for others..."
+			buttonText = "ready.role_done"
 			buttonClass = "btn btn-success"
 		} else {
-			buttonText = "I've Seen My Role ✓"
+			buttonText = "ready.role"
 			buttonClass = "btn btn-primary"
 		}
 	case models.StatusPlaying:
 		buttonID = "ready-button-playing"
 		if isReady {
-			buttonText = "✓ Ready to Vote"
+			buttonText = "ready.vote_done"
 			buttonClass = "btn btn-success"
 		} else {
-			buttonText = "Ready to Vote?"
+			buttonText = "ready.vote"
 			buttonClass = "btn btn-secondary"
 		}
 	}
-	var bb strings.Builder
-	bb.WriteString(`<button id="`)
-	bb.WriteString(buttonID)
-	bb.WriteString(`" type="submit" class="`)
-	bb.WriteString(buttonClass)
-	bb.WriteString(`">`)
-	bb.WriteString(buttonText)
-	bb.WriteString(`</button>`)
-	buttonHTML = bb.String()
+	buttonHTML = ctx.ReadyButton(lobby.Language, buttonID, buttonClass, buttonText)
 
 	// Detailed logging for readiness change
-	if debug {
-		log.Printf("ready: room=%s phase=%s actor=%s(%s) prev=%v now=%v confirmed=[%s] count=%d/%d", roomCode, statusBefore, actorName, playerID, prev, isReady, strings.Join(confirmedNames, ", "), readyCount, totalPlayers)
-	}
+	logging.FromContext(r.Context()).Debug("Readiness toggled",
+		logging.Room(roomCode), logging.Player(playerID),
+		"phase", statusBefore, "actor", actorName, "prev", prev, "now", isReady,
+		"confirmed", confirmedNames, "ready", readyCount, "total", totalPlayers)
 
 	// Advance AFTER preparing current-phase outputs
 	nextPath := ""
 	if shouldAdvance {
 		switch statusBefore {
 		case models.StatusReadyCheck:
-			g.Status = models.StatusRoleReveal
-			// Pre-seed next phase readiness map
-			for id := range lobby.Players {
-				if _, ok := g.ReadyAfterReveal[id]; !ok {
-					g.ReadyAfterReveal[id] = false
-				}
-			}
+			game.SetStatus(g, models.StatusRoleReveal)
 			nextPath = game.PhasePathFor(roomCode, g.Status)
 			shouldBroadcastPhase = true
 		case models.StatusRoleReveal:
-			g.Status = models.StatusPlaying
-			// Record when playing phase started (for timer sync)
-			g.PlayStartedAt = time.Now()
-			// Pre-seed next phase readiness map
-			for id := range lobby.Players {
-				if _, ok := g.ReadyToVote[id]; !ok {
-					g.ReadyToVote[id] = false
-				}
-			}
-			// Choose random first questioner
-			playerIDs := make([]string, 0, len(lobby.Players))
-			for id := range lobby.Players {
-				playerIDs = append(playerIDs, id)
-			}
-			g.FirstQuestioner = playerIDs[rand.Intn(len(playerIDs))]
+			// Entering play starts the timer and picks the first questioner
+			game.SetStatus(g, models.StatusPlaying)
 			nextPath = game.PhasePathFor(roomCode, g.Status)
 			shouldBroadcastPhase = true
 		case models.StatusPlaying:
-			g.Status = models.StatusVoting
+			game.SetStatus(g, models.StatusVoting)
 			nextPath = game.PhasePathFor(roomCode, g.Status)
 			shouldBroadcastPhase = true
 		}
@@ -366,7 +336,7 @@ func (ctx *Context) gameHandleReadyCookie(w http.ResponseWriter, r *http.Request
 	if shouldBroadcastPhase {
 		sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
 		// Also ensure the initiating client navigates via HX-Redirect
-		w.Header().Set("HX-Redirect", nextPath)
+		w.Header().Set("HX-Redirect", ctx.Config.Path(nextPath))
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -375,287 +345,575 @@ func (ctx *Context) gameHandleReadyCookie(w http.ResponseWriter, r *http.Request
 	w.Write([]byte(buttonHTML))
 }
 
-// gameHandleVoteCookie records a vote using cookie-based player ID
-func (ctx *Context) gameHandleVoteCookie(w http.ResponseWriter, r *http.Request, roomCode string) {
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
+// HandleVote records the player's ballot. Its shape depends on the game's
+// voting system: one suspect, several approved ones, or a ranking. Unless the
+// lobby locks votes, it replaces the player's earlier ballot this round.
+func (ctx *Context) HandleVote(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+
+	r.ParseForm()
+
+	lobby.Lock()
+	g := lobby.CurrentGame
+	if g == nil || g.Status != models.StatusVoting {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_voting"), http.StatusBadRequest)
 		return
 	}
-
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	if g.Trial != nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.trial_running"), http.StatusBadRequest)
+		return
+	}
+	if _, ok := g.PlayerInfo[playerID]; !ok {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_in_round"), http.StatusConflict)
+		return
+	}
+	if _, voted := g.Votes[playerID]; voted && !canChangeVote(g, playerID) {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.vote_locked"), http.StatusBadRequest)
+		return
+	}
+	ballot, errKey := checkBallot(g, playerID, r.Form["suspect"])
+	if errKey != "" {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, errKey), http.StatusBadRequest)
 		return
 	}
-	playerID := cookie.Value
 
-	r.ParseForm()
-	suspectID := r.FormValue("suspect")
+	g.Record(models.GameEvent{Type: models.EventVoteCast, Player: playerID, Ballot: ballot})
+	ctx.afterVote(w, r, lobby, g)
+}
 
-	var voteCountMsg string
-	var shouldFinish bool
-	var shouldRevote bool
-	var scoresUpdated bool
+// HandleAccuse stops play so the player can accuse someone. The clock stands
+// still while the others give their verdicts.
+func (ctx *Context) HandleAccuse(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
+	accused := r.FormValue("suspect")
 
 	lobby.Lock()
 	g := lobby.CurrentGame
-	if g == nil || g.Status != models.StatusVoting {
+	if g == nil || g.Status != models.StatusPlaying {
 		lobby.Unlock()
-		http.Error(w, "Not in voting phase", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.invalid_phase"), http.StatusBadRequest)
+		return
+	}
+	if g.Trial != nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.trial_running"), http.StatusBadRequest)
+		return
+	}
+	if _, ok := g.PlayerInfo[playerID]; !ok {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_in_round"), http.StatusConflict)
+		return
+	}
+	if g.Accusations[playerID] >= g.Settings.Accusations {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.no_accusations_left"), http.StatusBadRequest)
+		return
+	}
+	if _, ok := g.PlayerInfo[accused]; !ok || accused == playerID {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.invalid_accusation"), http.StatusBadRequest)
 		return
 	}
 
-	g.Votes[playerID] = suspectID
+	g.Record(models.GameEvent{Type: models.EventAccusationMade, Player: playerID, Target: accused})
+	lobby.Unlock()
 
-	if len(g.Votes) == len(lobby.Players) {
-		// Count votes
-		voteCount := make(map[string]int)
-		for _, votedFor := range g.Votes {
-			voteCount[votedFor]++
-		}
+	logging.FromContext(r.Context()).Info("Play stopped for an accusation", "accused", accused)
 
-		maxVotes := 0
-		var playersWithMaxVotes []string
-		for pID, count := range voteCount {
-			if count > maxVotes {
-				maxVotes = count
-				playersWithMaxVotes = []string{pID}
-			} else if count == maxVotes {
-				playersWithMaxVotes = append(playersWithMaxVotes, pID)
-			}
-		}
+	// Everyone's play page reloads with the trial
+	nextPath := game.PhasePathFor(roomCode, models.StatusPlaying)
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
+	w.Header().Set("HX-Redirect", ctx.Config.Path(nextPath))
+	w.WriteHeader(http.StatusOK)
+}
 
-		if len(playersWithMaxVotes) > 1 && g.VoteRound < game.MaxVoteRounds {
-			// tie -> revote
-			g.Votes = make(map[string]string)
-			g.VoteRound++
-			shouldRevote = true
-		} else {
-			// finish game
-			g.Status = models.StatusFinished
-			innocentWon := len(playersWithMaxVotes) == 1 && playersWithMaxVotes[0] == g.SpyID
-			for id := range lobby.Players {
-				if id == g.SpyID {
-					if innocentWon {
-						lobby.Scores[id].GamesLost++
-					} else {
-						lobby.Scores[id].GamesWon++
-					}
-				} else {
-					if innocentWon {
-						lobby.Scores[id].GamesWon++
-					} else {
-						lobby.Scores[id].GamesLost++
-					}
-				}
-			}
-			shouldFinish = true
-			scoresUpdated = true
-		}
+// HandleVerdict records the player's guilty or not guilty verdict on the accused
+func (ctx *Context) HandleVerdict(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+
+	lobby.Lock()
+	g := lobby.CurrentGame
+	if g == nil || (g.Status != models.StatusVoting && g.Status != models.StatusPlaying) || g.Trial == nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.no_trial"), http.StatusBadRequest)
+		return
+	}
+	if g.Trial.Accused == playerID {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.accused_cannot_judge"), http.StatusBadRequest)
+		return
+	}
+	if g.Trial.Accuser == playerID {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.accuser_cannot_judge"), http.StatusBadRequest)
+		return
+	}
+	if _, ok := g.PlayerInfo[playerID]; !ok {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_in_round"), http.StatusConflict)
+		return
+	}
+	if _, given := g.Trial.Verdicts[playerID]; given && !canChangeVote(g, playerID) {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.vote_locked"), http.StatusBadRequest)
+		return
+	}
+	verdict := r.FormValue("verdict")
+	if verdict != "guilty" && verdict != "not_guilty" {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.invalid_verdict"), http.StatusBadRequest)
+		return
 	}
 
-	voteCountMsg = ctx.VoteCount(len(g.Votes), len(lobby.Players))
+	g.Record(models.GameEvent{Type: models.EventVerdictCast, Player: playerID, Guilty: verdict == "guilty"})
+	ctx.afterVote(w, r, lobby, g)
+}
+
+// afterVote resolves the round once everyone has voted, unlocks the lobby and
+// tells everyone where things stand (lock must be held; it is released)
+func (ctx *Context) afterVote(w http.ResponseWriter, r *http.Request, lobby *models.Lobby, g *models.Game) {
+	roomCode := lobby.Code
+	nextPath := ""
+	var results map[string]models.GameResult
+	if done, total := g.VoteProgress(len(lobby.Players)); done == total {
+		// A revote, a trial or resumed play starts the phase's page over
+		results = ctx.resolveRound(lobby, g)
+		nextPath = game.PhasePathFor(roomCode, g.Status)
+	}
+
+	lang := lobby.Language
+	done, total := g.VoteProgress(len(lobby.Players))
+	voteCountMsg := ctx.VoteCount(lang, done, total)
+	changePath := ""
+	if nextPath == "" && canChangeVote(g, playerFrom(r)) {
+		changePath = game.PhasePathFor(roomCode, g.Status)
+	}
 	lobby.Unlock()
 
+	ctx.recordResults(logging.FromContext(r.Context()), results)
+
 	sse.Broadcast(lobby, sse.EventVoteCount, voteCountMsg)
-	if scoresUpdated {
-		sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lobby.Players, lobby.Scores, lobby.Host))
+	if results != nil {
+		sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
 	}
-	if shouldRevote {
-		sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, game.PhasePathFor(roomCode, models.StatusVoting)))
-	} else if shouldFinish {
-		sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, game.PhasePathFor(roomCode, models.StatusFinished)))
+	if nextPath != "" {
+		sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
 	}
 
-w.Header().Set("Content-Type", "text/html")
-w.Write([]byte(ctx.VotedConfirmation()))
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(ctx.VotedConfirmation(lang, changePath)))
 }
 
-// handleWordCollectionPage renders the word collection page
-func (ctx *Context) handleWordCollectionPage(w http.ResponseWriter, r *http.Request, lobby *models.Lobby, playerID, roomCode string) {
-lobby.RLock()
-g := lobby.CurrentGame
-
-// Count words submitted
-wordsSubmittedCount := 0
-for _, submitted := range g.WordsSubmitted {
-if submitted {
-wordsSubmittedCount++
-}
+// canChangeVote reports whether playerID may change the vote or verdict they
+// cast in g's current round. The accuser's guilty verdict is fixed. (lock must be held)
+func canChangeVote(g *models.Game, playerID string) bool {
+	if t := g.Trial; t != nil && t.Accuser == playerID {
+		return false
+	}
+	return !g.Settings.LockVotes
 }
 
-// Check if player has submitted a word
-hasSubmittedWord := g.WordsSubmitted[playerID]
-submittedWord := ""
-if hasSubmittedWord {
-submittedWord = g.CustomWords[playerID]
+// checkBallot turns the posted picks into a ballot for g's voting system, or
+// returns the key of the error explaining why they don't make one (lock must be held)
+func checkBallot(g *models.Game, voter string, picks []string) (models.Ballot, string) {
+	if !slices.ContainsFunc(picks, func(p string) bool { return p != "" }) {
+		return nil, "error.vote_empty"
+	}
+	for _, p := range picks {
+		if p == "" {
+			// Ranked voting posts unused ranks empty
+			continue
+		}
+		if p == voter {
+			return nil, "error.vote_self"
+		}
+		if _, ok := g.PlayerInfo[p]; !ok {
+			return nil, "error.vote_not_player"
+		}
+	}
+	ballot, ok := game.Strategy(g.Settings.VotingSystem).Ballot(picks)
+	if !ok {
+		return nil, "error.invalid_ballot"
+	}
+	return ballot, ""
 }
 
-data := struct {
-RoomCode            string
-PlayerID            string
-Players             []*models.Player
-TotalPlayers        int
-HasSubmittedWord    bool
-SubmittedWord       string
-WordsSubmittedCount int
-WordsSubmitted      map[string]bool
-IsHost              bool
-}{
-RoomCode:            roomCode,
-PlayerID:            playerID,
-Players:             render.GetPlayerList(lobby.Players),
-TotalPlayers:        len(lobby.Players),
-HasSubmittedWord:    hasSubmittedWord,
-SubmittedWord:       submittedWord,
-WordsSubmittedCount: wordsSubmittedCount,
-WordsSubmitted:      g.WordsSubmitted,
-IsHost:              lobby.Host == playerID,
+// resolveRound acts on a voting round or live accusation whose ballots are all
+// in: a failed accusation resumes play, the accused goes on trial, an undecided
+// round is voted again while rounds are left, and otherwise the game finishes. Returns the results of a finished game. (lock must be held)
+func (ctx *Context) resolveRound(lobby *models.Lobby, g *models.Game) map[string]models.GameResult {
+	if t := g.Trial; t != nil && t.Live() {
+		// A live accusation either ends the game or play goes on
+		if t.Guilty() {
+			return ctx.finishGame(lobby, g, g.IsSpy(t.Accused))
+		}
+		g.Record(models.GameEvent{Type: models.EventPlayResumed})
+		return nil
+	}
+	result := game.CountVotes(g)
+	switch {
+	case result.Accused != "":
+		g.Record(models.GameEvent{Type: models.EventTrialStarted, Target: result.Accused})
+		return nil
+	case result.Undecided() && g.VoteRound < g.Settings.MaxVoteRounds:
+		g.Record(models.GameEvent{Type: models.EventRevoteStarted})
+		return nil
+	}
+	// Spies win or lose together, so one caught spy is enough
+	return ctx.finishGame(lobby, g, result.InnocentWon)
 }
-lobby.RUnlock()
 
-ctx.Templates.ExecuteTemplate(w, "game_word_collection.html", data)
-}
+// HandleGuessLocation lets a spy name the location during play. The game ends
+// either way: a right guess wins it for the spies, a wrong one loses it.
+func (ctx *Context) HandleGuessLocation(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
+	word := r.FormValue("location")
 
-// gameHandleSubmitWord handles word submission in custom words mode
-func (ctx *Context) gameHandleSubmitWord(w http.ResponseWriter, r *http.Request, roomCode string) {
-lobby, exists := ctx.LobbyStore.Get(roomCode)
-if !exists {
-http.Error(w, "Lobby not found", http.StatusNotFound)
-return
-}
+	lobby.Lock()
+	g := lobby.CurrentGame
+	if g == nil || g.Status != models.StatusPlaying {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.invalid_phase"), http.StatusBadRequest)
+		return
+	}
+	if g.Trial != nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.trial_running"), http.StatusBadRequest)
+		return
+	}
+	if _, ok := g.PlayerInfo[playerID]; !ok {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_in_round"), http.StatusConflict)
+		return
+	}
+	if !g.IsSpy(playerID) {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.guess_not_spy"), http.StatusForbidden)
+		return
+	}
+	if !slices.Contains(ctx.guessOptions(lobby, g), word) {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.invalid_guess"), http.StatusBadRequest)
+		return
+	}
 
-cookie, err := r.Cookie("player_id")
-if err != nil {
-http.Error(w, "Unauthorized", http.StatusUnauthorized)
-return
-}
-playerID := cookie.Value
+	g.Record(models.GameEvent{Type: models.EventLocationGuessed, Player: playerID, Word: word})
+	correct := g.SpyGuess.Correct
+	results := ctx.finishGame(lobby, g, !correct)
+	lang := lobby.Language
+	lobby.Unlock()
 
-r.ParseForm()
-word := strings.TrimSpace(r.FormValue("word"))
-if word == "" {
-http.Error(w, "Word is required", http.StatusBadRequest)
-return
-}
+	logger := logging.FromContext(r.Context())
+	logger.Info("Spy guessed the location", "correct", correct)
+	ctx.recordResults(logger, results)
 
-// Sanitize word (limit length and clean up)
-if len(word) > 50 {
-word = word[:50]
+	nextPath := game.PhasePathFor(roomCode, models.StatusFinished)
+	sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
+	w.Header().Set("HX-Redirect", ctx.Config.Path(nextPath))
+	w.WriteHeader(http.StatusOK)
 }
 
-var wordCountMsg string
-var shouldAdvance bool
+// handleWordCollectionPage renders the word collection page
+func (ctx *Context) handleWordCollectionPage(w http.ResponseWriter, r *http.Request, lobby *models.Lobby, playerID, roomCode string) {
+	lobby.RLock()
+	g := lobby.CurrentGame
+
+	// Count words submitted
+	wordsSubmittedCount := 0
+	for _, submitted := range g.WordsSubmitted {
+		if submitted {
+			wordsSubmittedCount++
+		}
+	}
 
-lobby.Lock()
-g := lobby.CurrentGame
-if g == nil || g.Status != models.StatusWordCollection {
-lobby.Unlock()
-http.Error(w, "Not in word collection phase", http.StatusBadRequest)
-return
-}
+	// Check if player has submitted a word
+	hasSubmittedWord := g.WordsSubmitted[playerID]
+	submittedWord := ""
+	if hasSubmittedWord {
+		submittedWord = g.CustomWords[playerID]
+	}
 
-// Check if player already submitted
-if g.WordsSubmitted[playerID] {
-lobby.Unlock()
-http.Error(w, "Word already submitted", http.StatusBadRequest)
-return
+	data := struct {
+		RoomCode            string
+		PlayerID            string
+		Players             []*models.Player
+		TotalPlayers        int
+		HasSubmittedWord    bool
+		SubmittedWord       string
+		WordsSubmittedCount int
+		WordsSubmitted      map[string]bool
+		IsHost              bool
+		CanHost             bool
+	}{
+		RoomCode:            roomCode,
+		PlayerID:            playerID,
+		Players:             render.GetPlayerList(lobby.Players),
+		TotalPlayers:        len(lobby.Players),
+		HasSubmittedWord:    hasSubmittedWord,
+		SubmittedWord:       submittedWord,
+		WordsSubmittedCount: wordsSubmittedCount,
+		WordsSubmitted:      g.WordsSubmitted,
+		IsHost:              lobby.Host == playerID,
+		CanHost:             lobby.CanHost(playerID),
+	}
+	lobby.RUnlock()
+
+	ctx.render(w, r, "game_word_collection.html", data)
 }
 
-// Store the word
-g.CustomWords[playerID] = word
-g.WordsSubmitted[playerID] = true
+// HandleSubmitWord handles word submission in custom words mode
+func (ctx *Context) HandleSubmitWord(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
 
-// Count submitted words
-wordsSubmittedCount := 0
-for _, submitted := range g.WordsSubmitted {
-if submitted {
-wordsSubmittedCount++
-}
-}
-totalPlayers := len(lobby.Players)
-
-// Check if all words are submitted
-if wordsSubmittedCount == totalPlayers {
-// All words collected, now assign spy and select word
-ctx.assignSpyAndSelectWord(g, lobby.Players)
-
-// Advance to ready check phase
-g.Status = models.StatusReadyCheck
-// Pre-seed readiness map
-for id := range lobby.Players {
-g.ReadyToReveal[id] = false
-}
-shouldAdvance = true
-}
+	r.ParseForm()
+	word := strings.TrimSpace(r.FormValue("word"))
+	if word == "" {
+		ctx.Error(w, r, ctx.T(r, "error.word_required"), http.StatusBadRequest)
+		return
+	}
 
-wordCountMsg = ctx.WordCollectionCount(wordsSubmittedCount, totalPlayers)
-lobby.Unlock()
+	// Sanitize word (limit length and clean up)
+	if len(word) > 50 {
+		word = word[:50]
+	}
 
-// Broadcast word collection count update
-sse.Broadcast(lobby, "word-collection-count", wordCountMsg)
+	var wordCountMsg string
+	var shouldAdvance bool
 
-if shouldAdvance {
-// All words collected, advance to next phase
-nextPath := game.PhasePathFor(roomCode, models.StatusReadyCheck)
-sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
-w.Header().Set("HX-Redirect", nextPath)
-w.WriteHeader(http.StatusOK)
-return
-}
+	lobby.Lock()
+	g := lobby.CurrentGame
+	if g == nil || g.Status != models.StatusWordCollection {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.not_word_collection"), http.StatusBadRequest)
+		return
+	}
 
-// Return success message for individual word submission
-w.Header().Set("Content-Type", "text/html")
-w.Write([]byte(`<div class="text-center">
-<h2>✓ Word Submitted!</h2>
-<p>You submitted: <strong>"` + word + `"</strong></p>
-<p class="text-muted">Waiting for other players to submit their words...</p>
-</div>`))
-}
+	// Check if player already submitted
+	if g.WordsSubmitted[playerID] {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.word_already_submitted"), http.StatusBadRequest)
+		return
+	}
 
-// assignSpyAndSelectWord assigns a random spy and selects a random word from submissions
-func (ctx *Context) assignSpyAndSelectWord(g *models.Game, players map[string]*models.Player) {
-// Create list of all words
-words := make([]string, 0, len(g.CustomWords))
-for _, word := range g.CustomWords {
-words = append(words, word)
-}
+	// Store the word
+	g.Record(models.GameEvent{Type: models.EventWordSubmitted, Player: playerID, Word: word})
 
-// Select random word
-selectedWord := words[rand.Intn(len(words))]
-g.SelectedCustomWord = selectedWord
+	// Count submitted words
+	wordsSubmittedCount := 0
+	for _, submitted := range g.WordsSubmitted {
+		if submitted {
+			wordsSubmittedCount++
+		}
+	}
+	totalPlayers := len(lobby.Players)
 
-// Create location object for the selected word
-g.Location = &models.Location{
-Word:       selectedWord,
-Categories: []string{"custom"},
-}
+	// Check if all words are submitted
+	if wordsSubmittedCount == totalPlayers {
+		// All words collected, now assign spy and select word
+		ctx.setupGame(lobby, g)
+		logging.FromContext(r.Context()).Info("Custom words game set up",
+			logging.Room(roomCode), "word", g.SelectedCustomWord, "spies", len(g.Spies))
+
+		// Advance to ready check phase
+		game.SetStatus(g, models.StatusReadyCheck)
+		shouldAdvance = true
+	}
+
+	lang := lobby.Language
+	wordCountMsg = ctx.WordCollectionCount(lang, wordsSubmittedCount, totalPlayers)
+	lobby.Unlock()
+
+	// Broadcast word collection count update
+	sse.Broadcast(lobby, "word-collection-count", wordCountMsg)
 
-// Create list of player IDs
-playerIDs := make([]string, 0, len(players))
-for id := range players {
-playerIDs = append(playerIDs, id)
+	if shouldAdvance {
+		// All words collected, advance to next phase
+		nextPath := game.PhasePathFor(roomCode, models.StatusReadyCheck)
+		sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
+		w.Header().Set("HX-Redirect", ctx.Config.Path(nextPath))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Return success message for individual word submission
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(`<div class="text-center">` + ctx.ExecutePartial(lang, "word_submitted.html", word) + `</div>`))
+}
+
+// setupGame makes the game's random choices from its seed: the location (or
+// one of the players' words), spies, challenges and question order. The lobby
+// state they depend on is kept on the game so the setup can be replayed. (lock must be held)
+func (ctx *Context) setupGame(lobby *models.Lobby, g *models.Game) {
+	setup := models.GameSetup{
+		Input: &models.SetupInput{
+			Players:  make(map[string]string, len(lobby.Players)),
+			Language: lobby.Language,
+			History:  lobby.History.Clone(),
+		},
+	}
+	for id, p := range lobby.Players {
+		setup.Input.Players[id] = p.Name
+	}
+	if lobby.Series != nil {
+		setup.Input.SeriesTurns = maps.Clone(lobby.Series.SpyTurns)
+	}
+
+	rng := game.NewRand(g.Seed)
+	if g.Mode == models.GameModeCustomWords {
+		setup.CustomWord = selectCustomWord(rng, g)
+		setup.Location = &models.Location{Word: setup.CustomWord, Categories: []string{"custom"}}
+	} else {
+		setup.Location = ctx.pickLocation(rng, lobby, g.Settings.Categories)
+	}
+	ctx.assignRoles(rng, lobby, g.Settings, &setup)
+
+	setup.QuestionOrder = lobby.PlayerIDs()
+	rng.Shuffle(len(setup.QuestionOrder), func(i, j int) {
+		setup.QuestionOrder[i], setup.QuestionOrder[j] = setup.QuestionOrder[j], setup.QuestionOrder[i]
+	})
+	g.Record(models.GameEvent{Type: models.EventSetupDrawn, Setup: &setup})
+}
+
+// selectCustomWord picks a random word from the submissions as the location
+func selectCustomWord(rng *rand.Rand, g *models.Game) string {
+	// Sorted, so the pick only depends on rng
+	words := slices.Sorted(maps.Values(g.CustomWords))
+	return words[rng.Intn(len(words))]
+}
+
+// assignRoles picks settings.SpyCount spies with the configured strategy and
+// hands every player a challenge in the lobby's language (unless the lobby plays
+// without them), preferring ones the lobby hasn't seen yet (lock must be held)
+func (ctx *Context) assignRoles(rng *rand.Rand, lobby *models.Lobby, settings models.LobbySettings, setup *models.GameSetup) {
+	setup.Spies = make(map[string]string)
+	for _, id := range ctx.pickSpies(rng, lobby, max(settings.SpyCount, 1)) {
+		setup.Spies[id] = lobby.Players[id].Name
+		lobby.History.RecordSpy(id)
+		if lobby.Series != nil {
+			lobby.Series.SpyTurns[id]++
+		}
+	}
+
+	var challenges []string
+	if !settings.NoChallenges {
+		all := ctx.challenges(lobby.Language)
+		challenges = unused(all, lobby.History.Challenges, len(lobby.Players))
+		if challenges == nil {
+			// Everyone's seen (nearly) every challenge; start over
+			lobby.History.Challenges = nil
+			challenges = slices.Clone(all)
+		}
+		rng.Shuffle(len(challenges), func(i, j int) {
+			challenges[i], challenges[j] = challenges[j], challenges[i]
+		})
+	}
+
+	setup.Challenges = make(map[string]string, len(lobby.Players))
+	for i, id := range lobby.PlayerIDs() {
+		setup.Challenges[id] = ""
+		if len(challenges) > 0 {
+			setup.Challenges[id] = challenges[i%len(challenges)]
+			lobby.History.RecordChallenge(setup.Challenges[id])
+		}
+	}
 }
 
-// Assign random spy
-spyID := playerIDs[rand.Intn(len(playerIDs))]
-g.SpyID = spyID
-g.SpyName = players[spyID].Name
-
-// Assign challenges and roles
-shuffledChallenges := make([]string, len(ctx.Challenges))
-copy(shuffledChallenges, ctx.Challenges)
-rand.Shuffle(len(shuffledChallenges), func(i, j int) {
-shuffledChallenges[i], shuffledChallenges[j] = shuffledChallenges[j], shuffledChallenges[i]
-})
-
-for i, id := range playerIDs {
-g.PlayerInfo[id] = &models.GamePlayerInfo{
-Challenge: shuffledChallenges[i%len(shuffledChallenges)],
-IsSpy:     id == g.SpyID,
+// pickSpies chooses n spies with the configured strategy. During a series,
+// players with fewer spy turns in it are always picked first. (lock must be held)
+func (ctx *Context) pickSpies(rng *rand.Rand, lobby *models.Lobby, n int) []string {
+	groups := [][]string{lobby.PlayerIDs()}
+	if lobby.Series != nil {
+		groups = lobby.Series.ByTurns(groups[0])
+	}
+	var spies []string
+	for _, group := range groups {
+		if len(spies) == n {
+			break
+		}
+		spies = append(spies, game.PickSpies(rng, ctx.Config.Game.SpySelection, group, n-len(spies), &lobby.History)...)
+	}
+	return spies
 }
+
+// unused returns the items not in used, or nil if fewer than want are left
+func unused(items []string, used map[string]bool, want int) []string {
+	var fresh []string
+	for _, item := range items {
+		if !used[item] {
+			fresh = append(fresh, item)
+		}
+	}
+	if len(fresh) < want {
+		return nil
+	}
+	return fresh
+}
+
+// pickLocation draws a random location in the lobby's language from the given
+// categories, skipping ones the lobby already played until they run out.
+// With no categories, or none that match, every location is eligible. (lock must be held)
+func (ctx *Context) pickLocation(rng *rand.Rand, lobby *models.Lobby, categories []string) *models.Location {
+	eligible := ctx.eligibleLocations(lobby.Language, categories)
+	var fresh []models.Location
+	for _, loc := range eligible {
+		if !lobby.History.Locations[loc.Word] {
+			fresh = append(fresh, loc)
+		}
+	}
+	if len(fresh) == 0 {
+		// Every eligible location has been played; start over
+		lobby.History.Locations = nil
+		fresh = eligible
+	}
+	loc := fresh[rng.Intn(len(fresh))]
+	lobby.History.RecordLocation(loc.Word)
+	return &loc
+}
+
+// eligibleLocations returns the locations in lang from the given categories, or
+// every location when there are no categories or none match
+func (ctx *Context) eligibleLocations(lang string, categories []string) []models.Location {
+	locations := ctx.locations(lang)
+	var eligible []models.Location
+	for _, loc := range locations {
+		for _, c := range loc.Categories {
+			if slices.Contains(categories, c) {
+				eligible = append(eligible, loc)
+				break
+			}
+		}
+	}
+	if len(eligible) == 0 {
+		return locations
+	}
+	return eligible
 }
 
-log.Printf("Custom words game: selected word='%s' spy=%s(%s)", selectedWord, g.SpyName, spyID)
+// guessOptions lists what a spy may guess the location is: every location the
+// game's could have been drawn from, or the players' words, sorted. The
+// locations are in the language the setup drew from, falling back to the
+// lobby's for a game without one. (lock must be held)
+func (ctx *Context) guessOptions(lobby *models.Lobby, g *models.Game) []string {
+	if g.Mode == models.GameModeCustomWords {
+		return slices.Compact(slices.Sorted(maps.Values(g.CustomWords)))
+	}
+	lang := lobby.Language
+	if g.Setup != nil {
+		lang = g.Setup.Language
+	}
+	var words []string
+	for _, loc := range ctx.eligibleLocations(lang, g.Settings.Categories) {
+		words = append(words, loc.Word)
+	}
+	slices.Sort(words)
+	return words
 }
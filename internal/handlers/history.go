@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// lobbyArchive is the downloadable form of a lobby's game history
+type lobbyArchive struct {
+	Lobby string              `json:"lobby"`
+	Games []models.GameRecord `json:"games"`
+}
+
+// HandleHistory lists the lobby's finished games, newest first
+func (ctx *Context) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+
+	lobby.RLock()
+	games := slices.Clone(lobby.Archive)
+	lobby.RUnlock()
+	slices.Reverse(games)
+
+	ctx.render(w, r, "history.html", struct {
+		RoomCode string
+		Games    []models.GameRecord
+	}{
+		RoomCode: lobby.Code,
+		Games:    games,
+	})
+}
+
+// HandleHistoryDownload serves the lobby's game history as a JSON file
+func (ctx *Context) HandleHistoryDownload(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+
+	lobby.RLock()
+	archive := lobbyArchive{Lobby: lobby.Code, Games: slices.Clone(lobby.Archive)}
+	lobby.RUnlock()
+	if archive.Games == nil {
+		archive.Games = []models.GameRecord{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="sus-%s-history.json"`, lobby.Code))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(archive)
+}
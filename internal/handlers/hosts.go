@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
+)
+
+// hostVotesNeeded is the strict majority needed to replace the host, counted
+// over the players who can vote: everyone in the lobby but the host
+func hostVotesNeeded(players int) int {
+	return (players-1)/2 + 1
+}
+
+// promoteByVote makes host whoever a strict majority of the other players voted
+// for and returns their ID, or "" if nobody has enough votes (lock must be held)
+func promoteByVote(lobby *models.Lobby) string {
+	needed := hostVotesNeeded(len(lobby.Players))
+	for _, candidateID := range lobby.HostVotes {
+		if lobby.HostVoteCount(candidateID) >= needed {
+			lobby.SetHost(candidateID)
+			return candidateID
+		}
+	}
+	return ""
+}
+
+// announceVotedHost tells hostID they were voted host and everyone else who was
+func (ctx *Context) announceVotedHost(lobby *models.Lobby, lang, hostID, hostName string) {
+	sse.BroadcastPersonalized(lobby, func(pid string) string {
+		if pid == hostID {
+			return ctx.HostNotification(lang, "host_notification.title", "host_notification.voted_text")
+		}
+		return ctx.HostNotification(lang, "host_changed.title", "host_changed.text", hostName)
+	}, sse.EventHostChanged)
+}
+
+// HandleSetCoHost lets the host grant or revoke a player's co-host role.
+// Co-hosts have every host privilege but can't appoint other co-hosts.
+func (ctx *Context) HandleSetCoHost(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	targetID := r.FormValue("player")
+	coHost := r.FormValue("co_host") != ""
+
+	lobby.Lock()
+	if lobby.Host != playerID {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.host_only"), http.StatusForbidden)
+		return
+	}
+	target, ok := lobby.Players[targetID]
+	if !ok || targetID == lobby.Host {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.player_not_in_lobby"), http.StatusBadRequest)
+		return
+	}
+	lobby.SetCoHost(targetID, coHost)
+	lang := lobby.Language
+	lobby.Unlock()
+
+	logging.FromContext(r.Context()).Info("Co-host changed", "target", targetID, "name", target.Name, "co_host", coHost)
+
+	if coHost {
+		sse.BroadcastToPlayer(lobby, targetID, sse.EventHostChanged,
+			ctx.HostNotification(lang, "co_host_notification.title", "co_host_notification.text"))
+	}
+	sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
+	sse.BroadcastPersonalized(lobby, func(pid string) string {
+		return ctx.HostControls(lobby, pid)
+	}, sse.EventControlsUpdate)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleHostVote records a vote to replace the host. Once a strict majority of
+// the other players backs the same player, they become host and everyone is told.
+func (ctx *Context) HandleHostVote(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	candidateID := r.FormValue("candidate")
+	logger := logging.FromContext(r.Context())
+
+	lobby.Lock()
+	if lobby.Host == playerID {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.host_cannot_vote"), http.StatusBadRequest)
+		return
+	}
+	candidate, ok := lobby.Players[candidateID]
+	if !ok || candidateID == lobby.Host {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.player_not_in_lobby"), http.StatusBadRequest)
+		return
+	}
+
+	votes := lobby.VoteForHost(playerID, candidateID)
+	oldHost := lobby.Host
+	replaced := promoteByVote(lobby) == candidateID
+	lang := lobby.Language
+	lobby.Unlock()
+
+	logger.Info("Host vote cast", "candidate", candidateID, "votes", votes, "replaced", replaced)
+
+	if replaced {
+		logger.Info("Host replaced by vote", "old_host", oldHost, "new_host", candidateID)
+		ctx.announceVotedHost(lobby, lang, candidateID, candidate.Name)
+		sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
+	}
+	sse.BroadcastPersonalized(lobby, func(pid string) string {
+		return ctx.HostControls(lobby, pid)
+	}, sse.EventControlsUpdate)
+
+	w.WriteHeader(http.StatusOK)
+}
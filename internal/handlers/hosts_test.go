@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostVotesNeeded(t *testing.T) {
+	// players in the lobby, host included -> votes needed from the others
+	tests := map[int]int{2: 1, 3: 2, 4: 2, 5: 3, 6: 3, 7: 4}
+	for players, want := range tests {
+		if got := hostVotesNeeded(players); got != want {
+			t.Errorf("hostVotesNeeded(%d) = %d, want %d", players, got, want)
+		}
+	}
+}
+
+func TestLeavingCanCompleteHostVote(t *testing.T) {
+	ctx := withPages(t, testContext())
+	ctx.Config.Game.MinPlayers = 3
+	lobby := testLobby("ann", "ben", "cat", "dan", "eve")
+	lobby.Host = "ann"
+	// Two of the four who can vote want cat, one short of a majority
+	lobby.VoteForHost("ben", "cat")
+	lobby.VoteForHost("dan", "cat")
+	if promoteByVote(lobby) != "" {
+		t.Fatal("promoted without a majority")
+	}
+
+	// With eve gone, two of three is a majority
+	r := httptest.NewRequest(http.MethodPost, "/lobby/TEST/leave", nil)
+	ctx.handleLeaveLogic(httptest.NewRecorder(), r, lobby, "eve", "")
+	if lobby.Host != "cat" || lobby.HostVotes != nil {
+		t.Errorf("host %q with votes %v, want cat with the votes cleared", lobby.Host, lobby.HostVotes)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
+)
+
+// lang returns the language to answer r in: the lobby's language for lobby
+// routes, otherwise the best match for the browser's Accept-Language
+func (ctx *Context) lang(r *http.Request) string {
+	if lang, ok := r.Context().Value(langKey).(string); ok {
+		return lang
+	}
+	return ctx.I18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// T translates key into the request's language
+func (ctx *Context) T(r *http.Request, key string, args ...any) string {
+	return ctx.I18n.T(ctx.lang(r), key, args...)
+}
+
+// locations returns the places for lang, falling back to the default language
+func (ctx *Context) locations(lang string) []models.Location {
+	if locations, ok := ctx.Locations[lang]; ok {
+		return locations
+	}
+	return ctx.Locations[i18n.DefaultLanguage]
+}
+
+// categories lists the distinct location categories in lang, sorted
+func (ctx *Context) categories(lang string) []string {
+	var categories []string
+	for _, loc := range ctx.locations(lang) {
+		for _, c := range loc.Categories {
+			if !slices.Contains(categories, c) {
+				categories = append(categories, c)
+			}
+		}
+	}
+	slices.Sort(categories)
+	return categories
+}
+
+// challenges returns the challenges for lang, falling back to the default language
+func (ctx *Context) challenges(lang string) []string {
+	if challenges, ok := ctx.Challenges[lang]; ok {
+		return challenges
+	}
+	return ctx.Challenges[i18n.DefaultLanguage]
+}
+
+// HandleSetLanguage lets the host switch the lobby language between games;
+// everyone's lobby page reloads in the new language
+func (ctx *Context) HandleSetLanguage(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+	roomCode := lobby.Code
+
+	lang := r.FormValue("language")
+	if !ctx.I18n.Supports(lang) {
+		ctx.Error(w, r, ctx.T(r, "error.unsupported_language"), http.StatusBadRequest)
+		return
+	}
+
+	lobby.Lock()
+	if lobby.CurrentGame != nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.game_in_progress"), http.StatusBadRequest)
+		return
+	}
+	lobby.Language = lang
+	// Categories are named per language, so the filter can't carry over
+	lobby.Settings.Categories = nil
+	lobby.Unlock()
+
+	logging.FromContext(r.Context()).Info("Lobby language changed", "language", lang)
+
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, "/lobby/"+roomCode))
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/lobby/"+roomCode))
+	w.WriteHeader(http.StatusOK)
+}
@@ -2,30 +2,41 @@ package handlers
 
 import (
 	"bytes"
-	"html/template"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
 
+	"github.com/aaronzipp/you-are-officially-sus/internal/config"
+	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 	"github.com/aaronzipp/you-are-officially-sus/internal/render"
+	"github.com/aaronzipp/you-are-officially-sus/internal/scoring"
 	"github.com/aaronzipp/you-are-officially-sus/internal/store"
 )
 
 // Context holds shared application dependencies
 type Context struct {
 	LobbyStore *store.LobbyStore
-	Templates  *template.Template
-	Locations  []models.Location
-	Challenges []string
-	BaseURL    string
+	Accounts   *store.AccountStore
+	Scoring    *scoring.Engine
+	Seeds      game.SeedSource // seeds for game setups
+	Templates  *render.Templates
+	I18n       *i18n.Catalog
+	Locations  map[string][]models.Location // language -> places
+	Challenges map[string][]string          // language -> challenges
+	Config     *config.Config
+
+	shutdown shutdownState
 }
 
-// ExecutePartial executes a template partial and returns the HTML string
-func (ctx *Context) ExecutePartial(name string, data interface{}) string {
+// ExecutePartial executes a template partial in lang and returns the HTML string
+func (ctx *Context) ExecutePartial(lang, name string, data interface{}) string {
 	var buf bytes.Buffer
-	if err := ctx.Templates.ExecuteTemplate(&buf, name, data); err != nil {
+	if err := ctx.Templates.ExecuteTemplate(&buf, lang, name, data); err != nil {
 		// Log error to help debug template issues
-		log.Printf("ERROR: ExecutePartial failed for %s: %v (data type: %T)", name, err, data)
+		slog.Error("ExecutePartial failed", "template", name, "error", err, "data_type", fmt.Sprintf("%T", data))
 		return ""
 	}
 	return buf.String()
@@ -36,9 +47,11 @@ type playerListViewData struct {
 	Scores     map[string]*models.PlayerScore
 	HasResults bool
 	HostID     string
+	CoHosts    map[string]bool
 }
 
-func (ctx *Context) buildPlayerListData(players map[string]*models.Player, scores map[string]*models.PlayerScore, hostID string) playerListViewData {
+func (ctx *Context) buildPlayerListData(lobby *models.Lobby) playerListViewData {
+	players, scores := lobby.Players, lobby.Scores
 	hasResults := false
 	for _, score := range scores {
 		if score == nil {
@@ -61,82 +74,195 @@ func (ctx *Context) buildPlayerListData(players map[string]*models.Player, score
 		Players:    orderedPlayers,
 		Scores:     scores,
 		HasResults: hasResults,
-		HostID:     hostID,
+		HostID:     lobby.Host,
+		CoHosts:    lobby.CoHosts,
 	}
 }
 
 // PlayerList generates HTML for the player list using template partials
-func (ctx *Context) PlayerList(players map[string]*models.Player, scores map[string]*models.PlayerScore, hostID string) string {
-	data := ctx.buildPlayerListData(players, scores, hostID)
-	return ctx.ExecutePartial("player_list.html", data)
+func (ctx *Context) PlayerList(lang string, lobby *models.Lobby) string {
+	data := ctx.buildPlayerListData(lobby)
+	return ctx.ExecutePartial(lang, "player_list.html", data)
 }
 
-// HostControls generates HTML for host controls using template partials
-func (ctx *Context) HostControls(lobby *models.Lobby, playerID string) string {
+type hostControlsViewData struct {
+	IsHost      bool // host or co-host
+	IsMainHost  bool
+	PlayerCount int
+	InGame      bool
+	RoomCode    string
+	HostName    string
+	MinPlayers  int
+	Language    string
+	Languages   []i18n.Language
+	Settings    models.LobbySettings // normalized
+	Categories  []categoryOption     // every category in the lobby language
+
+	// Co-host management for the host, host votes for everyone else
+	Members     []memberOption // everyone but the host
+	MyHostVote  string
+	VotesNeeded int
+
+	// Choices offered by the settings panel
+	SpyCounts        []int
+	DiscussionLimits []int
+	VoteRoundLimits  []int
+	VotingSystems    []models.VotingSystem
+	AccusationLimits []int
+	SeriesRounds     []int // series lengths long enough for everyone to be a spy
+}
+
+// categoryOption is one category checkbox in the settings panel
+type categoryOption struct {
+	Name    string
+	Checked bool
+}
+
+// memberOption is a player the host can make co-host or the others can vote in as host
+type memberOption struct {
+	ID        string
+	Name      string
+	CoHost    bool
+	HostVotes int
+}
+
+// discussionMinuteChoices are the timer lengths offered to the host
+var discussionMinuteChoices = []int{3, 5, 8, 10, 15, 20, 30}
+
+// buildHostControlsData describes the lobby status card as seen by playerID (lock must be held)
+func (ctx *Context) buildHostControlsData(lobby *models.Lobby, playerID string) hostControlsViewData {
 	hostName := ""
 	if host, ok := lobby.Players[lobby.Host]; ok && host != nil {
 		hostName = host.Name
 	}
-	return ctx.ExecutePartial("host_controls.html", struct {
-		IsHost      bool
-		PlayerCount int
-		InGame      bool
-		RoomCode    string
-		HostName    string
-	}{
-		IsHost:      lobby.Host == playerID,
+	settings := lobby.Settings.Normalized(ctx.Config.Game.MaxVoteRounds)
+	var categories []categoryOption
+	for _, c := range ctx.categories(lobby.Language) {
+		// No filter means every category is in play
+		checked := len(settings.Categories) == 0 || slices.Contains(settings.Categories, c)
+		categories = append(categories, categoryOption{Name: c, Checked: checked})
+	}
+	var members []memberOption
+	for _, p := range render.GetPlayerList(lobby.Players) {
+		if p.ID == lobby.Host {
+			continue
+		}
+		members = append(members, memberOption{
+			ID:        p.ID,
+			Name:      p.Name,
+			CoHost:    lobby.CoHosts[p.ID],
+			HostVotes: lobby.HostVoteCount(p.ID),
+		})
+	}
+	minRounds, maxRounds := ctx.seriesRoundLimits(lobby)
+	var seriesRounds []int
+	for n := minRounds; n <= maxRounds; n++ {
+		seriesRounds = append(seriesRounds, n)
+	}
+	return hostControlsViewData{
+		IsHost:      lobby.CanHost(playerID),
+		IsMainHost:  lobby.Host == playerID,
 		PlayerCount: len(lobby.Players),
 		InGame:      lobby.CurrentGame != nil,
 		RoomCode:    lobby.Code,
 		HostName:    hostName,
-	})
+		MinPlayers:  ctx.Config.Game.MinPlayers,
+		Language:    lobby.Language,
+		Languages:   ctx.I18n.Languages(),
+		Settings:    settings,
+		Categories:  categories,
+
+		Members:     members,
+		MyHostVote:  lobby.HostVotes[playerID],
+		VotesNeeded: hostVotesNeeded(len(lobby.Players)),
+
+		SpyCounts:        countUpTo(models.MaxSpyCount),
+		DiscussionLimits: discussionMinuteChoices,
+		VoteRoundLimits:  countUpTo(max(models.MaxVoteRoundsLimit, ctx.Config.Game.MaxVoteRounds)),
+		VotingSystems:    models.VotingSystems,
+		AccusationLimits: countUpTo(models.MaxAccusations),
+		SeriesRounds:     seriesRounds,
+	}
+}
+
+// countUpTo returns 1..n
+func countUpTo(n int) []int {
+	nums := make([]int, n)
+	for i := range nums {
+		nums[i] = i + 1
+	}
+	return nums
 }
 
-// ReadyCount generates HTML for ready count display
-func (ctx *Context) ReadyCount(ready, total int, label string) string {
-	return ctx.ExecutePartial("ready_count.html", struct {
+// HostControls generates HTML for host controls using template partials
+func (ctx *Context) HostControls(lobby *models.Lobby, playerID string) string {
+	return ctx.ExecutePartial(lobby.Language, "host_controls.html", ctx.buildHostControlsData(lobby, playerID))
+}
+
+// ReadyCount generates HTML for ready count display; labelKey is a catalog key
+// formatted with the ready and total counts
+func (ctx *Context) ReadyCount(lang string, ready, total int, labelKey string) string {
+	return ctx.ExecutePartial(lang, "ready_count.html", struct {
 		ReadyCount int
 		TotalCount int
 		Label      string
 	}{
 		ReadyCount: ready,
 		TotalCount: total,
-		Label:      label,
+		Label:      labelKey,
 	})
 }
 
 // VoteCount generates HTML for vote count display
-func (ctx *Context) VoteCount(count, total int) string {
-return ctx.ExecutePartial("vote_count.html", struct {
-VoteCount  int
-TotalCount int
-}{
-VoteCount:  count,
-TotalCount: total,
-})
+func (ctx *Context) VoteCount(lang string, count, total int) string {
+	return ctx.ExecutePartial(lang, "vote_count.html", struct {
+		VoteCount  int
+		TotalCount int
+	}{
+		VoteCount:  count,
+		TotalCount: total,
+	})
 }
 
 // WordCollectionCount generates HTML for word collection count display
-func (ctx *Context) WordCollectionCount(submitted, total int) string {
-return ctx.ExecutePartial("ready_count.html", struct {
-ReadyCount int
-TotalCount int
-Label      string
-}{
-ReadyCount: submitted,
-TotalCount: total,
-Label:      "players have submitted words",
-})
+func (ctx *Context) WordCollectionCount(lang string, submitted, total int) string {
+	return ctx.ExecutePartial(lang, "ready_count.html", struct {
+		ReadyCount int
+		TotalCount int
+		Label      string
+	}{
+		ReadyCount: submitted,
+		TotalCount: total,
+		Label:      "count.words",
+	})
+}
+
+// ReadyButton generates HTML for a phase's ready toggle; textKey is a catalog key
+func (ctx *Context) ReadyButton(lang, id, class, textKey string) string {
+	return ctx.ExecutePartial(lang, "ready_button.html", struct {
+		ButtonID    string
+		ButtonClass string
+		ButtonText  string
+	}{
+		ButtonID:    id,
+		ButtonClass: class,
+		ButtonText:  textKey,
+	})
 }
 
-// VotedConfirmation generates HTML for "you voted" confirmation
-func (ctx *Context) VotedConfirmation() string {
-	return ctx.ExecutePartial("voted_confirmation.html", nil)
+// VotedConfirmation generates HTML for "you voted" confirmation. changePath is
+// the page to change the vote on, or empty if it can't change.
+func (ctx *Context) VotedConfirmation(lang, changePath string) string {
+	return ctx.ExecutePartial(lang, "voted_confirmation.html", struct {
+		ChangePath string
+	}{
+		ChangePath: changePath,
+	})
 }
 
 // ErrorMessage generates HTML for error messages
-func (ctx *Context) ErrorMessage(message string) string {
-	return ctx.ExecutePartial("error_message.html", struct {
+func (ctx *Context) ErrorMessage(lang, message string) string {
+	return ctx.ExecutePartial(lang, "error_message.html", struct {
 		Message string
 	}{
 		Message: message,
@@ -145,7 +271,7 @@ func (ctx *Context) ErrorMessage(message string) string {
 
 // RedirectSnippet returns an HTMX snippet that triggers a client-side redirect
 func (ctx *Context) RedirectSnippet(roomCode, to string) string {
-	return ctx.ExecutePartial("redirect_snippet.html", struct {
+	return ctx.ExecutePartial(i18n.DefaultLanguage, "redirect_snippet.html", struct {
 		RoomCode string
 		To       string
 	}{
@@ -155,24 +281,36 @@ func (ctx *Context) RedirectSnippet(roomCode, to string) string {
 }
 
 // GameAbortedMessage generates HTML for game aborted warning
-func (ctx *Context) GameAbortedMessage(reason string) string {
-	return ctx.ExecutePartial("game_aborted_message.html", struct {
+func (ctx *Context) GameAbortedMessage(lang, reason string) string {
+	return ctx.ExecutePartial(lang, "game_aborted_message.html", struct {
 		Reason string
 	}{
 		Reason: reason,
 	})
 }
 
-// HostNotification generates HTML for new host notification
-func (ctx *Context) HostNotification() string {
-	return ctx.ExecutePartial("host_notification.html", nil)
+// HostNotification generates HTML for a host change notice from catalog keys;
+// args are formatted into the text
+func (ctx *Context) HostNotification(lang, titleKey, textKey string, args ...any) string {
+	return ctx.ExecutePartial(lang, "host_notification.html", struct {
+		Title string
+		Text  string
+	}{
+		Title: ctx.I18n.T(lang, titleKey),
+		Text:  ctx.I18n.T(lang, textKey, args...),
+	})
 }
 
 // HandleIndex serves the landing page
 func (ctx *Context) HandleIndex(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
-		return
-	}
-	ctx.Templates.ExecuteTemplate(w, "index.html", nil)
+	account, _ := ctx.Accounts.ByToken(accountToken(r))
+	ctx.render(w, r, "index.html", struct {
+		MinPlayers     int
+		RoomCodeLength int
+		Nickname       string // prefills the name fields when signed in
+	}{
+		MinPlayers:     ctx.Config.Game.MinPlayers,
+		RoomCodeLength: ctx.Config.Game.RoomCodeLength,
+		Nickname:       account.Nickname,
+	})
 }
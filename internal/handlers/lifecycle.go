@@ -1,269 +1,137 @@
 package handlers
 
 import (
-	"log"
-	"math/rand"
+	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/metrics"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/scoring"
 	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
 )
 
 // HandleStartGame starts a new game in the lobby
 func (ctx *Context) HandleStartGame(w http.ResponseWriter, r *http.Request) {
-	log.Printf("HandleStartGame called: %s %s", r.Method, r.URL.Path)
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	roomCode := strings.TrimPrefix(r.URL.Path, "/start-game/")
-	gameMode := r.FormValue("mode")
-	if gameMode == "" {
-		gameMode = "standard" // default to standard mode
-	}
-
-	log.Printf("HandleStartGame: roomCode=%s mode=%s", roomCode, gameMode)
-
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		log.Printf("HandleStartGame: lobby %s not found", roomCode)
-		http.Error(w, "Lobby not found", http.StatusNotFound)
-		return
-	}
-
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		log.Printf("HandleStartGame: no player_id cookie")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-	playerID := cookie.Value
-
-	log.Printf("HandleStartGame: playerID=%s isHost=%v", playerID, lobby.Host == playerID)
+	logger := logging.FromContext(r.Context())
+	lobby := lobbyFrom(r)
+	roomCode := lobby.Code
 
 	lobby.Lock()
-
-	// Check if player is host
-	if lobby.Host != playerID {
+	if key, args := ctx.startGameError(lobby); key != "" {
 		lobby.Unlock()
-		log.Printf("HandleStartGame: player is not host")
-		http.Error(w, "Only host can start game", http.StatusForbidden)
+		logger.Info("Start game refused", "reason", key, "players", len(lobby.Players))
+		ctx.Error(w, r, ctx.T(r, key, args...), http.StatusBadRequest)
 		return
 	}
+	redirectPath := ctx.startGame(lobby)
+	lobby.Unlock()
 
-	if lobby.CurrentGame != nil {
-		lobby.Unlock()
-		log.Printf("HandleStartGame: game already in progress")
-		http.Error(w, "Game already in progress", http.StatusBadRequest)
-		return
-	}
-
-	if len(lobby.Players) < game.MinPlayers {
-		lobby.Unlock()
-		log.Printf("HandleStartGame: not enough players (%d)", len(lobby.Players))
-		http.Error(w, "Need at least 3 players", http.StatusBadRequest)
-		return
-	}
-
-	log.Printf("HandleStartGame: creating game for lobby %s", roomCode)
-
-// Create new game
-newGame := &models.Game{
-Mode:             models.GameMode(gameMode),
-PlayerInfo:       make(map[string]*models.GamePlayerInfo),
-ReadyToReveal:    make(map[string]bool),
-ReadyAfterReveal: make(map[string]bool),
-ReadyToVote:      make(map[string]bool),
-Votes:            make(map[string]string),
-VoteRound:        1,
-}
-
-// Set initial status and location based on game mode
-if gameMode == "custom_words" {
-newGame.Status = models.StatusWordCollection
-newGame.CustomWords = make(map[string]string)
-newGame.WordsSubmitted = make(map[string]bool)
-// Initialize word submission tracking
-for id := range lobby.Players {
-newGame.WordsSubmitted[id] = false
-}
-} else {
-newGame.Status = models.StatusReadyCheck
-newGame.Location = &ctx.Locations[rand.Intn(len(ctx.Locations))]
-// Pre-seed current phase readiness map with all players
-for id := range lobby.Players {
-newGame.ReadyToReveal[id] = false
-}
-}
+	logger.Info("Game started", "path", redirectPath)
 
-// For standard mode, assign spy and challenges immediately
-// For custom words mode, we delay spy assignment until after word collection
-if gameMode == "standard" {
-// Assign spy
-playerIDs := make([]string, 0, len(lobby.Players))
-for id := range lobby.Players {
-playerIDs = append(playerIDs, id)
-}
-spyID := playerIDs[rand.Intn(len(playerIDs))]
-newGame.SpyID = spyID
-newGame.SpyName = lobby.Players[spyID].Name
-
-// Assign challenges and roles
-shuffledChallenges := make([]string, len(ctx.Challenges))
-copy(shuffledChallenges, ctx.Challenges)
-rand.Shuffle(len(shuffledChallenges), func(i, j int) {
-shuffledChallenges[i], shuffledChallenges[j] = shuffledChallenges[j], shuffledChallenges[i]
-})
-
-for i, id := range playerIDs {
-newGame.PlayerInfo[id] = &models.GamePlayerInfo{
-Challenge: shuffledChallenges[i%len(shuffledChallenges)],
-IsSpy:     id == newGame.SpyID,
-}
-}
-}
+	// Broadcast HTMX redirect snippet to all clients
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, redirectPath))
 
-lobby.CurrentGame = newGame
-lobby.Unlock()
-
-// Determine redirect path based on game mode
-var redirectPath string
-if gameMode == "custom_words" {
-redirectPath = game.PhasePathFor(roomCode, models.StatusWordCollection)
-log.Printf("HandleStartGame: game created, broadcasting redirect to word-collection")
-} else {
-redirectPath = game.PhasePathFor(roomCode, models.StatusReadyCheck)
-log.Printf("HandleStartGame: game created, broadcasting redirect to confirm-reveal")
+	w.Header().Set("HX-Redirect", ctx.Config.Path(redirectPath))
+	w.WriteHeader(http.StatusOK)
 }
 
-// Broadcast HTMX redirect snippet to all clients
-sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, redirectPath))
-
-log.Printf("HandleStartGame: complete")
-w.Header().Set("HX-Redirect", redirectPath)
-w.WriteHeader(http.StatusOK)
+// startGameError returns the catalog key and arguments explaining why the
+// lobby can't start a game right now, or "" if it can (lock must be held)
+func (ctx *Context) startGameError(lobby *models.Lobby) (string, []any) {
+	settings := lobby.Settings.Normalized(ctx.Config.Game.MaxVoteRounds)
+	switch {
+	case lobby.CurrentGame != nil:
+		return "error.game_in_progress", nil
+	case len(lobby.Players) < ctx.Config.Game.MinPlayers:
+		return "error.need_players", []any{ctx.Config.Game.MinPlayers}
+	case settings.SpyCount*2 >= len(lobby.Players):
+		// Spies must stay a minority or a single vote can never catch one
+		return "error.too_many_spies", []any{settings.SpyCount, len(lobby.Players)}
+	}
+	return "", nil
 }
 
-// HandleRestartGame resets the game and returns to lobby
-func (ctx *Context) HandleRestartGame(w http.ResponseWriter, r *http.Request) {
-	log.Printf("HandleRestartGame called: %s %s", r.Method, r.URL.Path)
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+// startGame creates the lobby's next game from its settings, advancing the
+// series if one is running, and returns the path of the first phase (lock must be held)
+func (ctx *Context) startGame(lobby *models.Lobby) string {
+	settings := lobby.Settings.Normalized(ctx.Config.Game.MaxVoteRounds)
+	newGame := models.NewGame(settings, ctx.Seeds())
+	for _, id := range lobby.PlayerIDs() {
+		newGame.Record(models.GameEvent{Type: models.EventPlayerJoined, Player: id, Name: lobby.Players[id].Name})
 	}
-
-	roomCode := strings.TrimPrefix(r.URL.Path, "/restart-game/")
-
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
-		return
+	lobby.CurrentGame = newGame
+	lobby.History.StartGame()
+	if lobby.Series != nil {
+		lobby.Series.Round++
 	}
 
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		log.Printf("HandleRestartGame: no player_id cookie")
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
+	// Set initial status and location based on game mode
+	if settings.Mode == models.GameModeCustomWords {
+		game.SetStatus(newGame, models.StatusWordCollection)
+	} else {
+		game.SetStatus(newGame, models.StatusReadyCheck)
+		// Custom words mode delays the setup until after word collection
+		ctx.setupGame(lobby, newGame)
 	}
-	playerID := cookie.Value
+	metrics.GameStarted(string(settings.Mode))
 
-	lobby.Lock()
-
-	// Check if player is host
-	if lobby.Host != playerID {
-		lobby.Unlock()
-		log.Printf("HandleRestartGame: player %s is not host", playerID)
-		http.Error(w, "Only host can restart game", http.StatusForbidden)
-		return
-	}
+	return game.PhasePathFor(lobby.Code, newGame.Status)
+}
 
-	// Clear game
-	lobby.CurrentGame = nil
+// HandleRestartGame resets the game (and any series) and returns to lobby
+func (ctx *Context) HandleRestartGame(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context())
+	lobby := lobbyFrom(r)
+	roomCode := lobby.Code
 
+	// Clear game; returning to the lobby also ends a series
+	lobby.Lock()
+	lobby.EndCurrentGame()
+	lobby.Series = nil
 	lobby.Unlock()
 
-	log.Printf("HandleRestartGame: game cleared, broadcasting nav-redirect to lobby")
+	logger.Info("Game cleared, returning players to lobby")
 
 	// Broadcast restart WITHOUT holding lock
 	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, "/lobby/"+roomCode))
 
-	log.Printf("HandleRestartGame: sending redirect response")
-	w.Header().Set("HX-Redirect", "/lobby/"+roomCode)
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/lobby/"+roomCode))
 	w.WriteHeader(http.StatusOK)
 }
 
 // HandleCloseLobby deletes the lobby
 func (ctx *Context) HandleCloseLobby(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	roomCode := strings.TrimPrefix(r.URL.Path, "/close-lobby/")
+	lobby := lobbyFrom(r)
+	logging.FromContext(r.Context()).Info("Host closed lobby")
+	ctx.closeLobby(lobby)
 
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
-		return
-	}
-
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-	playerID := cookie.Value
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/"))
+	w.WriteHeader(http.StatusOK)
+}
 
-	lobby.Lock()
-	if lobby.Host != playerID {
-		lobby.Unlock()
-		http.Error(w, "Only host can close lobby", http.StatusForbidden)
-		return
+// closeLobby sends every connected client home and deletes the lobby.
+// Shared by the host's close button and the admin force-close.
+func (ctx *Context) closeLobby(lobby *models.Lobby) {
+	lobby.RLock()
+	if g := lobby.CurrentGame; g != nil && g.Status != models.StatusFinished {
+		metrics.GameFinished(string(g.Mode), metrics.OutcomeAborted)
 	}
-	lobby.Unlock()
+	lobby.RUnlock()
 
 	// Broadcast closure
-	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, "/"))
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(lobby.Code, "/"))
 
 	// Delete lobby
-	ctx.LobbyStore.Delete(roomCode)
-
-	w.Header().Set("HX-Redirect", "/")
-	w.WriteHeader(http.StatusOK)
+	ctx.LobbyStore.Delete(lobby.Code)
 }
 
 // HandleLeaveLobby allows a player to leave the lobby/game
 func (ctx *Context) HandleLeaveLobby(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	roomCode := strings.TrimPrefix(r.URL.Path, "/leave-lobby/")
-
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
-		return
-	}
-
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-	playerID := cookie.Value
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
 
 	lobby.RLock()
 	isHost := lobby.Host == playerID
@@ -272,41 +140,20 @@ func (ctx *Context) HandleLeaveLobby(w http.ResponseWriter, r *http.Request) {
 
 	// If host and there are other players, redirect to host selection page
 	if isHost && playerCount > 1 {
-		w.Header().Set("HX-Redirect", "/select-host/"+roomCode)
+		w.Header().Set("HX-Redirect", ctx.Config.Path("/select-host/"+roomCode))
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
 	// Otherwise, proceed with normal leave (auto-assign or last player)
-	ctx.handleLeaveLogic(w, r, roomCode, playerID, "")
+	ctx.handleLeaveLogic(w, r, lobby, playerID, "")
 }
 
 // HandleSelectHost shows the host selection page
 func (ctx *Context) HandleSelectHost(w http.ResponseWriter, r *http.Request) {
-	roomCode := strings.TrimPrefix(r.URL.Path, "/select-host/")
-
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
-		return
-	}
-
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
-	playerID := cookie.Value
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
 
 	lobby.RLock()
-	// Check if player is host
-	if lobby.Host != playerID {
-		lobby.RUnlock()
-		http.Redirect(w, r, "/lobby/"+roomCode, http.StatusSeeOther)
-		return
-	}
-
 	// Get other players (excluding current host)
 	type Player struct {
 		ID   string
@@ -322,7 +169,7 @@ func (ctx *Context) HandleSelectHost(w http.ResponseWriter, r *http.Request) {
 
 	// If no other players, just leave
 	if len(otherPlayers) == 0 {
-		ctx.handleLeaveLogic(w, r, roomCode, playerID, "")
+		ctx.handleLeaveLogic(w, r, lobby, playerID, "")
 		return
 	}
 
@@ -330,78 +177,62 @@ func (ctx *Context) HandleSelectHost(w http.ResponseWriter, r *http.Request) {
 		RoomCode     string
 		OtherPlayers []Player
 	}{
-		RoomCode:     roomCode,
+		RoomCode:     lobby.Code,
 		OtherPlayers: otherPlayers,
 	}
 
-	ctx.Templates.ExecuteTemplate(w, "select_host.html", data)
+	ctx.render(w, r, "select_host.html", data)
 }
 
 // HandleLeaveLobbyWithHost allows a host to leave after selecting a new host
 func (ctx *Context) HandleLeaveLobbyWithHost(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	roomCode := strings.TrimPrefix(r.URL.Path, "/leave-lobby-with-host/")
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
 
 	// Parse form to get new host selection
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.invalid_form"), http.StatusBadRequest)
 		return
 	}
 	newHostID := r.FormValue("new_host")
 	if newHostID == "" {
-		http.Error(w, "New host not selected", http.StatusBadRequest)
-		return
-	}
-
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		ctx.Error(w, r, ctx.T(r, "error.no_new_host"), http.StatusBadRequest)
 		return
 	}
-	playerID := cookie.Value
 
-	ctx.handleLeaveLogic(w, r, roomCode, playerID, newHostID)
+	ctx.handleLeaveLogic(w, r, lobby, playerID, newHostID)
 }
 
 // handleLeaveLogic contains the shared logic for leaving a lobby
 // If newHostID is provided, it will be used instead of auto-assignment
-func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roomCode, playerID, newHostID string) {
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Error(w, "Lobby not found", http.StatusNotFound)
-		return
-	}
-
+func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, lobby *models.Lobby, playerID, newHostID string) {
+	roomCode := lobby.Code
 	lobby.Lock()
 
 	// Check if player is in lobby
 	player, exists := lobby.Players[playerID]
 	if !exists {
 		lobby.Unlock()
-		http.Error(w, "Player not in lobby", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.player_not_in_lobby"), http.StatusBadRequest)
 		return
 	}
 
 	wasHost := lobby.Host == playerID
 	playerName := player.Name
 
-	log.Printf("Player leaving: code=%s playerID=%s name=%s wasHost=%v", roomCode, playerID, playerName, wasHost)
+	logger := logging.FromContext(r.Context())
+	logger.Info("Player leaving", "name", playerName, "was_host", wasHost)
 
 	// Remove player from lobby
 	delete(lobby.Players, playerID)
 	delete(lobby.Scores, playerID)
+	lobby.ForgetPlayer(playerID)
 
 	// Check if this was the last player
 	if len(lobby.Players) == 0 {
 		lobby.Unlock()
-		log.Printf("Last player left, deleting lobby: code=%s", roomCode)
+		logger.Info("Last player left, deleting lobby")
 		ctx.LobbyStore.Delete(roomCode)
-		w.Header().Set("HX-Redirect", "/")
+		w.Header().Set("HX-Redirect", ctx.Config.Path("/"))
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -412,27 +243,39 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 	if wasHost {
 		if newHostID != "" {
 			// Use the provided host ID (manual selection)
-			lobby.Host = newHostID
+			lobby.SetHost(newHostID)
 			assignedHostID = newHostID
-			log.Printf("Host manually assigned: code=%s newHost=%s", roomCode, newHostID)
+			logger.Info("Host manually assigned", "new_host", newHostID)
 		} else {
 			// Auto-assign new host
 			assignNewHost(lobby)
 			assignedHostID = lobby.Host
 			autoAssigned = true
-			log.Printf("Host auto-assigned: code=%s newHost=%s", roomCode, assignedHostID)
+			logger.Info("Host auto-assigned", "new_host", assignedHostID)
 		}
 	}
 
+	// With one player fewer, the host votes still standing may now be a majority
+	oldHost := lobby.Host
+	votedHostID, votedHostName := promoteByVote(lobby), ""
+	if votedHostID != "" {
+		votedHostName = lobby.Players[votedHostID].Name
+		logger.Info("Host replaced by vote", "old_host", oldHost, "new_host", votedHostID)
+	}
+
 	// Handle game state if game is in progress
 	gameEnded := false
 	innocentsWon := false
+	aborted := false
+	var results map[string]models.GameResult
 	phaseAdvanced := false
 	if lobby.CurrentGame != nil {
 		g := lobby.CurrentGame
 
-		// Check if spy left
-		spyLeft := g.SpyID == playerID
+		// Check if spy left; after the results are in that no longer forfeits
+		spyLeft := g.IsSpy(playerID) && g.Status != models.StatusFinished
+		// Leaving calls off a live accusation and play resumes
+		accusing := g.Trial != nil && g.Trial.Live()
 
 		// Remove player from game state
 		removePlayerFromGame(g, playerID)
@@ -440,43 +283,53 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 		// Check if game should end
 		if spyLeft {
 			// Spy left - innocents win
-			log.Printf("Spy left the game: code=%s spyName=%s", roomCode, g.SpyName)
-			g.Status = models.StatusFinished
-			g.SpyForfeited = true
+			logger.Info("Spy left the game", "spy_name", g.Spies[playerID])
 			innocentsWon = true
 			gameEnded = true
-
-			// Update scores for remaining players (they all win)
-			for id := range lobby.Players {
-				lobby.Scores[id].GamesWon++
-			}
-		} else if len(lobby.Players) < game.MinPlayers {
+			// Remaining players are scored: innocents win, any other spies lose with them
+			results = ctx.finishGame(lobby, g, true)
+		} else if len(lobby.Players) < ctx.Config.Game.MinPlayers {
 			// Too few players - end game
-			log.Printf("Too few players remaining: code=%s count=%d", roomCode, len(lobby.Players))
-			lobby.CurrentGame = nil
+			logger.Info("Too few players remaining, ending game", "players", len(lobby.Players))
+			// A finished game was already counted and goes to the archive
+			aborted = g.Status != models.StatusFinished
+			if aborted {
+				metrics.GameFinished(string(g.Mode), metrics.OutcomeAborted)
+			}
+			lobby.EndCurrentGame()
+			lobby.Series = nil
 			gameEnded = true
 		} else {
 			// Game continues - check if phase should advance now that player is removed
-			phaseAdvanced = checkAndAdvancePhase(ctx, lobby, roomCode)
+			phaseAdvanced, results = checkAndAdvancePhase(ctx, lobby, roomCode)
+			phaseAdvanced = phaseAdvanced || accusing
 		}
 	}
 
+	lang := lobby.Language
 	lobby.Unlock()
+	ctx.recordResults(logger, results)
 
 	// Send notification to new host if host was auto-assigned (not manually selected)
 	if assignedHostID != "" && autoAssigned {
-		hostNotification := ctx.HostNotification()
+		hostNotification := ctx.HostNotification(lang, "host_notification.title", "host_notification.text")
 		sse.BroadcastToPlayer(lobby, assignedHostID, sse.EventHostChanged, hostNotification)
 	}
+	if votedHostID != "" {
+		ctx.announceVotedHost(lobby, lang, votedHostID, votedHostName)
+	}
 
 	// Broadcast updates to remaining players
 	if gameEnded {
 		if innocentsWon {
 			// Redirect to results page
 			sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, game.PhasePathFor(roomCode, models.StatusFinished)))
+		} else if !aborted {
+			// Nothing left to show on the results page of a game too few players remain for
+			sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, "/lobby/"+roomCode))
 		} else {
 			// Game cancelled due to insufficient players - show warning then redirect
-			abortMsg := ctx.GameAbortedMessage("Not enough players remaining (minimum 3 required)")
+			abortMsg := ctx.GameAbortedMessage(lang, ctx.I18n.T(lang, "aborted.not_enough_players", ctx.Config.Game.MinPlayers))
 			sse.Broadcast(lobby, sse.EventErrorMessage, abortMsg)
 
 			// Wait a moment, then redirect to lobby
@@ -487,7 +340,7 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 		}
 	} else {
 		// Update player list and scores
-		sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lobby.Players, lobby.Scores, lobby.Host))
+		sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
 		sse.BroadcastPersonalized(lobby, func(pid string) string {
 			return ctx.HostControls(lobby, pid)
 		}, sse.EventControlsUpdate)
@@ -498,7 +351,7 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 			newPhase := lobby.CurrentGame.Status
 			lobby.RUnlock()
 			nextPath := game.PhasePathFor(roomCode, newPhase)
-			log.Printf("Broadcasting phase transition after player leave: code=%s path=%s", roomCode, nextPath)
+			logger.Info("Broadcasting phase transition after player leave", "path", nextPath)
 			sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, nextPath))
 		} else if lobby.CurrentGame != nil {
 			// Update ready/vote counts if in game and phase didn't advance
@@ -513,7 +366,7 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 					}
 				}
 				lobby.RUnlock()
-				sse.Broadcast(lobby, "ready-count-check", ctx.ReadyCount(readyCount, len(lobby.Players), "players ready"))
+				sse.Broadcast(lobby, "ready-count-check", ctx.ReadyCount(lang, readyCount, len(lobby.Players), "count.ready"))
 			case models.StatusRoleReveal:
 				readyCount := 0
 				for id := range lobby.Players {
@@ -522,7 +375,7 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 					}
 				}
 				lobby.RUnlock()
-				sse.Broadcast(lobby, "ready-count-reveal", ctx.ReadyCount(readyCount, len(lobby.Players), "players ready"))
+				sse.Broadcast(lobby, "ready-count-reveal", ctx.ReadyCount(lang, readyCount, len(lobby.Players), "count.ready"))
 			case models.StatusPlaying:
 				readyCount := 0
 				for id := range lobby.Players {
@@ -531,11 +384,11 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 					}
 				}
 				lobby.RUnlock()
-				sse.Broadcast(lobby, "ready-count-playing", ctx.ReadyCount(readyCount, len(lobby.Players), "players ready to vote"))
+				sse.Broadcast(lobby, "ready-count-playing", ctx.ReadyCount(lang, readyCount, len(lobby.Players), "count.ready_to_vote"))
 			case models.StatusVoting:
-				voteCount := len(g.Votes)
+				voted, voters := g.VoteProgress(len(lobby.Players))
 				lobby.RUnlock()
-				sse.Broadcast(lobby, "vote-count-voting", ctx.VoteCount(voteCount, len(lobby.Players)))
+				sse.Broadcast(lobby, "vote-count-voting", ctx.VoteCount(lang, voted, voters))
 			default:
 				lobby.RUnlock()
 			}
@@ -543,47 +396,124 @@ func (ctx *Context) handleLeaveLogic(w http.ResponseWriter, r *http.Request, roo
 	}
 
 	// Redirect leaving player to home
-	w.Header().Set("HX-Redirect", "/")
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/"))
 	w.WriteHeader(http.StatusOK)
 }
 
-// assignNewHost assigns a new host to the lobby (first player by ID)
+// assignNewHost assigns a new host to the lobby: the first co-host by ID, or
+// the first player by ID when there are no co-hosts (deterministic)
 func assignNewHost(lobby *models.Lobby) {
-	// Find first player by ID (deterministic)
 	var firstID string
+	firstIsCoHost := false
 	for id := range lobby.Players {
-		if firstID == "" || id < firstID {
-			firstID = id
+		coHost := lobby.CoHosts[id]
+		if firstID == "" || (coHost && !firstIsCoHost) || (coHost == firstIsCoHost && id < firstID) {
+			firstID, firstIsCoHost = id, coHost
 		}
 	}
-	lobby.Host = firstID
+	lobby.SetHost(firstID)
 }
 
-// removePlayerFromGame removes a player from all game state maps
-func removePlayerFromGame(g *models.Game, playerID string) {
-	delete(g.PlayerInfo, playerID)
-	delete(g.ReadyToReveal, playerID)
-	delete(g.ReadyAfterReveal, playerID)
-	delete(g.ReadyToVote, playerID)
-	delete(g.Votes, playerID)
+// finishGame ends g, scores it (spies win or lose together, points come from
+// ctx.Scoring) and returns each signed-in player's result for their account stats.
+// A game that has already finished is left as it is. (lock must be held)
+func (ctx *Context) finishGame(lobby *models.Lobby, g *models.Game, innocentWon bool) map[string]models.GameResult {
+	if g.Status == models.StatusFinished {
+		return nil
+	}
+	game.SetStatus(g, models.StatusFinished)
+	if innocentWon {
+		metrics.GameFinished(string(g.Mode), metrics.OutcomeInnocents)
+	} else {
+		metrics.GameFinished(string(g.Mode), metrics.OutcomeSpy)
+	}
+
+	// Player-submitted words aren't locations worth tracking
+	location := ""
+	if g.Location != nil && g.Mode == models.GameModeStandard {
+		location = g.Location.Word
+	}
+
+	// The count is settled once here; a spy forfeit or guess ends the game
+	// before the vote, so there is nothing to count
+	tally := &models.VoteResult{}
+	if !g.SpyForfeited && g.SpyGuess == nil {
+		tally = game.CountVotes(g)
+	}
+	g.Record(models.GameEvent{Type: models.EventGameFinished, InnocentWon: innocentWon, Tally: tally})
+	if s := lobby.Series; s != nil && !s.IsLastRound() {
+		time.AfterFunc(game.SeriesBreakSeconds*time.Second, func() { ctx.autoNextRound(lobby, g) })
+	}
+	g.Record(models.GameEvent{
+		Type:   models.EventPointsAwarded,
+		Awards: ctx.Scoring.Score(ctx.outcome(lobby, g)),
+	})
+
+	results := make(map[string]models.GameResult)
+	for id, player := range lobby.Players {
+		won := g.IsSpy(id) != innocentWon
+		lobby.Scores[id].Record(g.IsSpy(id), won, g.Awards[id])
+		if player.AccountID == "" {
+			continue
+		}
+		// A forfeit ends the game before everyone has voted, so votes don't count;
+		// a verdict in a trial is a vote too
+		voted := len(g.Votes[id]) > 0
+		if g.Trial != nil {
+			_, gaveVerdict := g.Trial.Verdicts[id]
+			voted = voted || gaveVerdict
+		}
+		voted = voted && !g.SpyForfeited
+		results[player.AccountID] = models.GameResult{
+			Won:            won,
+			WasSpy:         g.IsSpy(id),
+			Voted:          voted,
+			VotedCorrectly: voted && tally.VotedCorrectly[id],
+			Location:       location,
+		}
+	}
+	return results
+}
+
+// rescoreGame re-runs the scoring rules on a finished game after something they
+// look at changed (a challenge review) and moves the difference into the lobby
+// scores (lock must be held)
+func (ctx *Context) rescoreGame(lobby *models.Lobby, g *models.Game) {
+	awards := ctx.Scoring.Score(ctx.outcome(lobby, g))
+	for _, id := range lobby.PlayerIDs() {
+		lobby.Scores[id].Points += models.TotalPoints(awards[id]) - models.TotalPoints(g.Awards[id])
+	}
+	g.Record(models.GameEvent{Type: models.EventPointsAwarded, Awards: awards})
+}
 
-	// Update first questioner if it was the leaving player
-	if g.FirstQuestioner == playerID {
-		g.FirstQuestioner = ""
+// outcome is what the scoring rules see of the finished game g (lock must be held)
+func (ctx *Context) outcome(lobby *models.Lobby, g *models.Game) scoring.Outcome {
+	return scoring.Outcome{
+		Game:           g,
+		Players:        lobby.PlayerIDs(),
+		InnocentWon:    g.InnocentWon,
+		VotedCorrectly: g.Tally.VotedCorrectly,
 	}
 }
 
+// removePlayerFromGame removes a player from all game state maps
+func removePlayerFromGame(g *models.Game, playerID string) {
+	g.Record(models.GameEvent{Type: models.EventPlayerLeft, Player: playerID})
+}
+
 // checkAndAdvancePhase checks if the game should advance to the next phase after a player leaves
-// Returns true if phase advanced, false otherwise
+// Returns true if phase advanced, false otherwise, and the results of a game the
+// departure finished
 // Caller must hold lobby lock
-func checkAndAdvancePhase(ctx *Context, lobby *models.Lobby, roomCode string) bool {
+func checkAndAdvancePhase(ctx *Context, lobby *models.Lobby, roomCode string) (bool, map[string]models.GameResult) {
 	if lobby.CurrentGame == nil {
-		return false
+		return false, nil
 	}
 
 	g := lobby.CurrentGame
 	totalPlayers := len(lobby.Players)
 	shouldAdvance := false
+	var results map[string]models.GameResult
 
 	switch g.Status {
 	case models.StatusReadyCheck:
@@ -595,14 +525,8 @@ func checkAndAdvancePhase(ctx *Context, lobby *models.Lobby, roomCode string) bo
 		}
 		shouldAdvance = readyCount == totalPlayers
 		if shouldAdvance {
-			log.Printf("Phase advancement after player leave: code=%s phase=%s->%s readyCount=%d/%d", roomCode, g.Status, models.StatusRoleReveal, readyCount, totalPlayers)
-			g.Status = models.StatusRoleReveal
-			// Pre-seed next phase readiness map
-			for id := range lobby.Players {
-				if _, ok := g.ReadyAfterReveal[id]; !ok {
-					g.ReadyAfterReveal[id] = false
-				}
-			}
+			slog.Info("Phase advancement after player leave", logging.Room(roomCode), "from", g.Status, "to", models.StatusRoleReveal, "ready", readyCount, "total", totalPlayers)
+			game.SetStatus(g, models.StatusRoleReveal)
 		}
 
 	case models.StatusRoleReveal:
@@ -614,24 +538,9 @@ func checkAndAdvancePhase(ctx *Context, lobby *models.Lobby, roomCode string) bo
 		}
 		shouldAdvance = readyCount == totalPlayers
 		if shouldAdvance {
-			log.Printf("Phase advancement after player leave: code=%s phase=%s->%s readyCount=%d/%d", roomCode, g.Status, models.StatusPlaying, readyCount, totalPlayers)
-			g.Status = models.StatusPlaying
-			// Record when playing phase started
-			g.PlayStartedAt = time.Now()
-			// Pre-seed next phase readiness map
-			for id := range lobby.Players {
-				if _, ok := g.ReadyToVote[id]; !ok {
-					g.ReadyToVote[id] = false
-				}
-			}
-			// Choose random first questioner if not set
-			if g.FirstQuestioner == "" {
-				playerIDs := make([]string, 0, len(lobby.Players))
-				for id := range lobby.Players {
-					playerIDs = append(playerIDs, id)
-				}
-				g.FirstQuestioner = playerIDs[rand.Intn(len(playerIDs))]
-			}
+			slog.Info("Phase advancement after player leave", logging.Room(roomCode), "from", g.Status, "to", models.StatusPlaying, "ready", readyCount, "total", totalPlayers)
+			// Entering play starts the timer and picks the first questioner
+			game.SetStatus(g, models.StatusPlaying)
 		}
 
 	case models.StatusPlaying:
@@ -643,160 +552,20 @@ func checkAndAdvancePhase(ctx *Context, lobby *models.Lobby, roomCode string) bo
 		}
 		shouldAdvance = readyCount > totalPlayers/2
 		if shouldAdvance {
-			log.Printf("Phase advancement after player leave: code=%s phase=%s->%s readyCount=%d/%d", roomCode, g.Status, models.StatusVoting, readyCount, totalPlayers)
-			g.Status = models.StatusVoting
+			slog.Info("Phase advancement after player leave", logging.Room(roomCode), "from", g.Status, "to", models.StatusVoting, "ready", readyCount, "total", totalPlayers)
+			game.SetStatus(g, models.StatusVoting)
 		}
 
 	case models.StatusVoting:
-		voteCount := len(g.Votes)
-		shouldAdvance = voteCount == totalPlayers
+		voteCount, voters := g.VoteProgress(totalPlayers)
+		shouldAdvance = voteCount == voters
 		if shouldAdvance {
-			log.Printf("All votes collected after player leave: code=%s votes=%d/%d", roomCode, voteCount, totalPlayers)
-			// Vote calculation is handled separately in gameHandleVoteCookie
-			// Here we just note that all votes are in
+			slog.Info("All votes collected after player leave", logging.Room(roomCode), "votes", voteCount, "total", totalPlayers)
+			// The leaver's ballot was the last one missing, so the round is
+			// resolved here as afterVote would have after the final vote
+			results = ctx.resolveRound(lobby, g)
 		}
 	}
 
-	return shouldAdvance
-}
-
-// handlePlayerDisconnect is called when a player's SSE connection is lost (browser closed/refreshed)
-// This handles automatic cleanup without the player explicitly clicking "Leave"
-func (ctx *Context) handlePlayerDisconnect(roomCode, playerID string) {
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		return
-	}
-
-	lobby.Lock()
-
-	// Check if player is still in lobby
-	player, exists := lobby.Players[playerID]
-	if !exists {
-		lobby.Unlock()
-		return
-	}
-
-	wasHost := lobby.Host == playerID
-	playerName := player.Name
-
-	log.Printf("Player disconnected: code=%s playerID=%s name=%s wasHost=%v", roomCode, playerID, playerName, wasHost)
-
-	// Remove player from lobby
-	delete(lobby.Players, playerID)
-	delete(lobby.Scores, playerID)
-
-	// Check if this was the last player
-	if len(lobby.Players) == 0 {
-		lobby.Unlock()
-		log.Printf("Last player disconnected, deleting lobby: code=%s", roomCode)
-		ctx.LobbyStore.Delete(roomCode)
-		return
-	}
-
-	// Reassign host if necessary (auto-assign on disconnect)
-	newHostID := ""
-	if wasHost {
-		assignNewHost(lobby)
-		newHostID = lobby.Host
-		log.Printf("Host disconnected, reassigned: code=%s newHost=%s", roomCode, newHostID)
-	}
-
-	// Handle game state if game is in progress
-	gameEnded := false
-	innocentsWon := false
-	if lobby.CurrentGame != nil {
-		g := lobby.CurrentGame
-
-		// Check if spy disconnected
-		spyLeft := g.SpyID == playerID
-
-		// Remove player from game state
-		removePlayerFromGame(g, playerID)
-
-		// Check if game should end
-		if spyLeft {
-			// Spy left - innocents win
-			log.Printf("Spy disconnected from game: code=%s spyName=%s", roomCode, g.SpyName)
-			g.Status = models.StatusFinished
-			g.SpyForfeited = true
-			innocentsWon = true
-			gameEnded = true
-
-			// Update scores for remaining players (they all win)
-			for id := range lobby.Players {
-				lobby.Scores[id].GamesWon++
-			}
-		} else if len(lobby.Players) < game.MinPlayers {
-			// Too few players - end game
-			log.Printf("Too few players remaining after disconnect: code=%s count=%d", roomCode, len(lobby.Players))
-			lobby.CurrentGame = nil
-			gameEnded = true
-		}
-	}
-
-	lobby.Unlock()
-
-	// Send notification to new host if host changed
-	if newHostID != "" {
-		hostNotification := ctx.HostNotification()
-		sse.BroadcastToPlayer(lobby, newHostID, sse.EventHostChanged, hostNotification)
-	}
-
-	// Broadcast updates to remaining players
-	if gameEnded {
-		if innocentsWon {
-			// Redirect to results page
-			sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, game.PhasePathFor(roomCode, models.StatusFinished)))
-		} else {
-			// Game cancelled due to insufficient players - show warning then redirect
-			abortMsg := ctx.GameAbortedMessage("Not enough players remaining (minimum 3 required)")
-			sse.Broadcast(lobby, sse.EventErrorMessage, abortMsg)
-
-			// Wait a moment, then redirect to lobby
-			go func() {
-				time.Sleep(3 * time.Second)
-				sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, "/lobby/"+roomCode))
-			}()
-		}
-	} else {
-		// Update player list and scores
-		sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lobby.Players, lobby.Scores, lobby.Host))
-		sse.BroadcastPersonalized(lobby, func(pid string) string {
-			return ctx.HostControls(lobby, pid)
-		}, sse.EventControlsUpdate)
-
-		// Update ready/vote counts if in game
-		if lobby.CurrentGame != nil {
-			g := lobby.CurrentGame
-			switch g.Status {
-			case models.StatusReadyCheck:
-				readyCount := 0
-				for id := range lobby.Players {
-					if g.ReadyToReveal[id] {
-						readyCount++
-					}
-				}
-				sse.Broadcast(lobby, "ready-count-check", ctx.ReadyCount(readyCount, len(lobby.Players), "players ready"))
-			case models.StatusRoleReveal:
-				readyCount := 0
-				for id := range lobby.Players {
-					if g.ReadyAfterReveal[id] {
-						readyCount++
-					}
-				}
-				sse.Broadcast(lobby, "ready-count-reveal", ctx.ReadyCount(readyCount, len(lobby.Players), "players ready"))
-			case models.StatusPlaying:
-				readyCount := 0
-				for id := range lobby.Players {
-					if g.ReadyToVote[id] {
-						readyCount++
-					}
-				}
-				sse.Broadcast(lobby, "ready-count-playing", ctx.ReadyCount(readyCount, len(lobby.Players), "players ready to vote"))
-			case models.StatusVoting:
-				sse.Broadcast(lobby, "vote-count-voting", ctx.VoteCount(len(g.Votes), len(lobby.Players)))
-			}
-		}
-	}
+	return shouldAdvance, results
 }
@@ -4,11 +4,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"html/template"
-	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
 	"github.com/google/uuid"
@@ -17,62 +18,51 @@ import (
 
 // HandleCreateLobby creates a new lobby
 func (ctx *Context) HandleCreateLobby(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// Don't open new lobbies while the server is draining
+	if ctx.rejectIfDraining(w, r) {
 		return
 	}
 
 	r.ParseForm()
 	hostName := strings.TrimSpace(r.FormValue("name"))
 	if hostName == "" {
-		http.Error(w, "Name is required", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.name_required"), http.StatusBadRequest)
 		return
 	}
 
 	playerID := uuid.New().String()
-	roomCode := game.GetUniqueRoomCode(ctx.LobbyStore)
+	roomCode := game.GetUniqueRoomCode(ctx.LobbyStore, ctx.Config.Game.RoomCodeLength)
 
 	lobby := &models.Lobby{
-		Code:    roomCode,
-		Host:    playerID,
-		Players: make(map[string]*models.Player),
-		Scores:  make(map[string]*models.PlayerScore),
-	}
-	lobby.Players[playerID] = &models.Player{ID: playerID, Name: hostName}
+		Code:      roomCode,
+		Host:      playerID,
+		Players:   make(map[string]*models.Player),
+		Scores:    make(map[string]*models.PlayerScore),
+		CreatedAt: time.Now(),
+		Language:  ctx.lang(r),
+	}
+	lobby.Players[playerID] = &models.Player{ID: playerID, Name: hostName, AccountID: ctx.accountID(r)}
 	lobby.Scores[playerID] = &models.PlayerScore{}
 
 	ctx.LobbyStore.Set(roomCode, lobby)
 
-	log.Printf("Created lobby: code=%s host=%s", roomCode, playerID)
+	logging.FromContext(r.Context()).Info("Created lobby", logging.Room(roomCode), logging.Player(playerID))
 
 	// Set cookie for player ID (session)
-	http.SetCookie(w, &http.Cookie{
-		Name:     "player_id",
-		Value:    playerID,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		// Secure: true, // enable when serving over HTTPS
-	})
+	ctx.setSessionCookie(w, r, playerID)
 
 	// Redirect to lobby
-	w.Header().Set("HX-Redirect", "/lobby/"+roomCode)
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/lobby/"+roomCode))
 	w.WriteHeader(http.StatusOK)
 }
 
 // HandleJoinLobby allows a player to join an existing lobby
 func (ctx *Context) HandleJoinLobby(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	r.ParseForm()
 
-	// Room code can come from URL path (/join/ABCD) or form field (legacy)
-	roomCode := strings.TrimPrefix(r.URL.Path, "/join/")
-	if roomCode == "" || roomCode == "/join" {
-		// Fall back to form field if not in URL
+	// Room code comes from the URL path (/join/ABCD) or the landing page form (/join)
+	roomCode := r.PathValue("code")
+	if roomCode == "" {
 		roomCode = strings.TrimSpace(r.FormValue("code"))
 	}
 	roomCode = strings.ToUpper(roomCode)
@@ -80,35 +70,36 @@ func (ctx *Context) HandleJoinLobby(w http.ResponseWriter, r *http.Request) {
 	playerName := strings.TrimSpace(r.FormValue("name"))
 
 	if roomCode == "" || playerName == "" {
-		http.Error(w, "Room code and name are required", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.code_and_name_required"), http.StatusBadRequest)
 		return
 	}
 
+	logger := logging.FromContext(r.Context()).With(logging.Room(roomCode))
+
 	lobby, exists := ctx.LobbyStore.Get(roomCode)
 	if !exists {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+		http.Redirect(w, r, ctx.Config.Path("/"), http.StatusSeeOther)
 		return
 	}
 
 	lobby.Lock()
+	lang := lobby.Language
 	if lobby.CurrentGame != nil {
 		lobby.Unlock()
-		http.Error(w, "Game in progress", http.StatusBadRequest)
+		ctx.Error(w, r, ctx.T(r, "error.game_in_progress"), http.StatusBadRequest)
 		return
 	}
 
 	// Check if browser already has a player_id cookie
 	var playerID string
 	var isRejoin bool
-	cookie, err := r.Cookie("player_id")
-	if err == nil && cookie.Value != "" {
-		existingPlayerID := cookie.Value
+	if existingPlayerID := sessionPlayerID(r); existingPlayerID != "" {
 		// Check if this player is already in the lobby
 		if _, exists := lobby.Players[existingPlayerID]; exists {
 			lobby.Unlock()
-			log.Printf("Player already in lobby: code=%s playerID=%s", roomCode, existingPlayerID)
+			logger.Info("Player already in lobby", logging.Player(existingPlayerID))
 			// Already joined - just redirect to lobby
-			w.Header().Set("HX-Redirect", "/lobby/"+roomCode)
+			w.Header().Set("HX-Redirect", ctx.Config.Path("/lobby/"+roomCode))
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -124,91 +115,65 @@ func (ctx *Context) HandleJoinLobby(w http.ResponseWriter, r *http.Request) {
 	// Check if name is already taken by another player
 	if isNameTaken(lobby.Players, playerName, playerID) {
 		lobby.Unlock()
-		log.Printf("Name already taken: code=%s name=%s playerID=%s", roomCode, playerName, playerID)
+		logger.Info("Name already taken", logging.Player(playerID), "name", playerName)
 		// Use HTMX response headers to retarget the error message
 		w.Header().Set("Content-Type", "text/html")
 		w.Header().Set("HX-Retarget", "#join-error")
 		w.Header().Set("HX-Reswap", "outerHTML")
 		w.WriteHeader(http.StatusOK)
-		errorMsg := fmt.Sprintf("The name \"%s\" is already taken. Please choose a different name.", playerName)
-		w.Write([]byte(ctx.ErrorMessage(errorMsg)))
+		errorMsg := ctx.I18n.T(lang, "error.name_taken", playerName)
+		w.Write([]byte(ctx.ErrorMessage(lang, errorMsg)))
 		return
 	}
 
 	// Log the successful join/rejoin
 	if isRejoin {
-		log.Printf("Player rejoined lobby: code=%s playerID=%s name=%s", roomCode, playerID, playerName)
+		logger.Info("Player rejoined lobby", logging.Player(playerID), "name", playerName)
 	} else {
-		log.Printf("Player joined lobby: code=%s playerID=%s name=%s", roomCode, playerID, playerName)
+		logger.Info("Player joined lobby", logging.Player(playerID), "name", playerName)
 	}
 
 	// Add/re-add player to lobby
-	lobby.Players[playerID] = &models.Player{ID: playerID, Name: playerName}
+	lobby.Players[playerID] = &models.Player{ID: playerID, Name: playerName, AccountID: ctx.accountID(r)}
 	if _, scoreExists := lobby.Scores[playerID]; !scoreExists {
 		lobby.Scores[playerID] = &models.PlayerScore{}
 	}
 	lobby.Unlock()
 
 	// Broadcast update to all clients
-	sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lobby.Players, lobby.Scores, lobby.Host))
+	sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
 	sse.BroadcastPersonalized(lobby, func(pid string) string {
 		return ctx.HostControls(lobby, pid)
 	}, sse.EventControlsUpdate)
 
 	// Set cookie for player ID (session)
-	http.SetCookie(w, &http.Cookie{
-		Name:     "player_id",
-		Value:    playerID,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		// Secure: true, // enable when serving over HTTPS
-	})
+	ctx.setSessionCookie(w, r, playerID)
 
 	// Redirect to lobby
-	w.Header().Set("HX-Redirect", "/lobby/"+roomCode)
+	w.Header().Set("HX-Redirect", ctx.Config.Path("/lobby/"+roomCode))
 	w.WriteHeader(http.StatusOK)
 }
 
 // HandleLobby displays the lobby page
 func (ctx *Context) HandleLobby(w http.ResponseWriter, r *http.Request) {
-	roomCode := strings.TrimPrefix(r.URL.Path, "/lobby/")
-
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
-
-	// Get player ID from cookie
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		// No cookie - redirect to join screen for this lobby
-		http.Redirect(w, r, "/join/"+roomCode, http.StatusSeeOther)
-		return
-	}
-	playerID := cookie.Value
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
 
 	lobby.RLock()
 	defer lobby.RUnlock()
 
 	// Generate QR code for lobby URL (only if BASE_URL is configured)
 	var qrDataURL template.URL
-	if ctx.BaseURL != "" {
-		lobbyURL := fmt.Sprintf("%s/lobby/%s", ctx.BaseURL, roomCode)
+	if lobbyURL := ctx.Config.PublicURL("/lobby/" + roomCode); lobbyURL != "" {
 		png, err := qrcode.Encode(lobbyURL, qrcode.Medium, 256)
 		if err != nil {
-			log.Printf("Failed to generate QR code for lobby %s: %v", roomCode, err)
+			logging.FromContext(r.Context()).Error("Failed to generate QR code", "error", err)
 		} else {
 			qrDataURL = template.URL(fmt.Sprintf("data:image/png;base64,%s", base64.StdEncoding.EncodeToString(png)))
 		}
 	}
 
-	listData := ctx.buildPlayerListData(lobby.Players, lobby.Scores, lobby.Host)
-	hostName := ""
-	if host, ok := lobby.Players[lobby.Host]; ok && host != nil {
-		hostName = host.Name
-	}
+	listData := ctx.buildPlayerListData(lobby)
 
 	data := struct {
 		RoomCode      string
@@ -218,8 +183,10 @@ func (ctx *Context) HandleLobby(w http.ResponseWriter, r *http.Request) {
 		Scores        map[string]*models.PlayerScore
 		HasResults    bool
 		HostID        string
-		HostName      string
+		CoHosts       map[string]bool
 		QRCodeDataURL template.URL
+		HostControls  hostControlsViewData
+		ArchivedGames int
 	}{
 		RoomCode:      lobby.Code,
 		PlayerID:      playerID,
@@ -228,49 +195,35 @@ func (ctx *Context) HandleLobby(w http.ResponseWriter, r *http.Request) {
 		Scores:        listData.Scores,
 		HasResults:    listData.HasResults,
 		HostID:        lobby.Host,
-		HostName:      hostName,
+		CoHosts:       listData.CoHosts,
 		QRCodeDataURL: qrDataURL,
+		HostControls:  ctx.buildHostControlsData(lobby, playerID),
+		ArchivedGames: len(lobby.Archive),
 	}
 
-	ctx.Templates.ExecuteTemplate(w, "lobby.html", data)
+	ctx.render(w, r, "lobby.html", data)
 }
 
 // HandleJoinLobbyScreen displays the join screen for entering name when scanning QR code
 func (ctx *Context) HandleJoinLobbyScreen(w http.ResponseWriter, r *http.Request) {
-	roomCode := strings.TrimPrefix(r.URL.Path, "/join/")
-
-	// Verify the lobby exists
-	_, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
+	lobby := lobbyFrom(r)
+	roomCode := lobby.Code
 
-	// Check if user already has a cookie - if so, redirect directly to lobby
-	cookie, err := r.Cookie("player_id")
-	if err == nil && cookie.Value != "" {
-		// Has cookie - redirect to lobby (HandleLobby will handle the rest)
-		http.Redirect(w, r, "/lobby/"+roomCode, http.StatusSeeOther)
+	// Players already in the lobby go straight there
+	lobby.RLock()
+	_, member := lobby.Players[sessionPlayerID(r)]
+	lobby.RUnlock()
+	if member {
+		http.Redirect(w, r, ctx.Config.Path("/lobby/"+roomCode), http.StatusSeeOther)
 		return
 	}
 
-	// No cookie - show join screen
+	// Otherwise show the join screen
 	data := struct {
 		RoomCode string
 	}{
 		RoomCode: roomCode,
 	}
 
-	ctx.Templates.ExecuteTemplate(w, "join_lobby.html", data)
-}
-
-// HandleJoinMux multiplexes between GET (join screen) and POST (join action) for /join/{roomCode}
-func (ctx *Context) HandleJoinMux(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodGet {
-		ctx.HandleJoinLobbyScreen(w, r)
-	} else if r.Method == http.MethodPost {
-		ctx.HandleJoinLobby(w, r)
-	} else {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
+	ctx.render(w, r, "join_lobby.html", data)
 }
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// sessionCookieName holds the player ID for the browser session
+const sessionCookieName = "player_id"
+
+type requestKey int
+
+const (
+	lobbyKey requestKey = iota
+	playerKey
+	langKey
+)
+
+// lobbyFrom returns the lobby loaded by withLobby
+func lobbyFrom(r *http.Request) *models.Lobby {
+	lobby, _ := r.Context().Value(lobbyKey).(*models.Lobby)
+	return lobby
+}
+
+// playerFrom returns the player ID authenticated by withMember
+func playerFrom(r *http.Request) string {
+	id, _ := r.Context().Value(playerKey).(string)
+	return id
+}
+
+// sessionPlayerID returns the player ID from the session cookie, or "" if there is none
+func sessionPlayerID(r *http.Request) string {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// isPageRequest reports whether a failure should redirect (full page loads)
+// rather than return an error status (HTMX actions)
+func isPageRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.Header.Get("HX-Request") != "true"
+}
+
+// withLobby loads the lobby named by the {code} path value.
+// Unknown lobbies send page loads home and fail actions with 404.
+func (ctx *Context) withLobby(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code := r.PathValue("code")
+		lobby, exists := ctx.LobbyStore.Get(code)
+		if !exists {
+			if isPageRequest(r) {
+				http.Redirect(w, r, ctx.Config.Path("/"), http.StatusSeeOther)
+				return
+			}
+			ctx.Error(w, r, ctx.T(r, "error.lobby_not_found"), http.StatusNotFound)
+			return
+		}
+
+		logger := logging.FromContext(r.Context()).With(logging.Room(code))
+		reqCtx := context.WithValue(logging.WithLogger(r.Context(), logger), lobbyKey, lobby)
+
+		// Resolve the lobby language now so later rendering never needs the lock
+		lobby.RLock()
+		lang := lobby.Language
+		lobby.RUnlock()
+		if lang != "" {
+			reqCtx = context.WithValue(reqCtx, langKey, lang)
+		}
+		next(w, r.WithContext(reqCtx))
+	}
+}
+
+// withMember loads the lobby and requires the session player to belong to it.
+// Page loads from outsiders go to the join screen (or home once a game is running).
+func (ctx *Context) withMember(next http.HandlerFunc) http.HandlerFunc {
+	return ctx.withLobby(func(w http.ResponseWriter, r *http.Request) {
+		lobby := lobbyFrom(r)
+		playerID := sessionPlayerID(r)
+
+		lobby.RLock()
+		_, member := lobby.Players[playerID]
+		inGame := lobby.CurrentGame != nil
+		lobby.RUnlock()
+
+		if playerID == "" || !member {
+			switch {
+			case isPageRequest(r) && !inGame:
+				http.Redirect(w, r, ctx.Config.Path("/join/"+lobby.Code), http.StatusSeeOther)
+			case isPageRequest(r):
+				http.Redirect(w, r, ctx.Config.Path("/"), http.StatusSeeOther)
+			case playerID == "":
+				ctx.Error(w, r, ctx.T(r, "error.unauthorized"), http.StatusUnauthorized)
+			default:
+				ctx.Error(w, r, ctx.T(r, "error.not_member"), http.StatusForbidden)
+			}
+			return
+		}
+
+		logger := logging.FromContext(r.Context()).With(logging.Player(playerID))
+		reqCtx := context.WithValue(logging.WithLogger(r.Context(), logger), playerKey, playerID)
+		next(w, r.WithContext(reqCtx))
+	})
+}
+
+// withHost is withMember restricted to the lobby host and co-hosts
+func (ctx *Context) withHost(next http.HandlerFunc) http.HandlerFunc {
+	return ctx.withMember(func(w http.ResponseWriter, r *http.Request) {
+		lobby := lobbyFrom(r)
+		lobby.RLock()
+		isHost := lobby.CanHost(playerFrom(r))
+		lobby.RUnlock()
+
+		if !isHost {
+			logging.FromContext(r.Context()).Info("Rejected host-only request")
+			if isPageRequest(r) {
+				http.Redirect(w, r, ctx.Config.Path("/lobby/"+lobby.Code), http.StatusSeeOther)
+				return
+			}
+			ctx.Error(w, r, ctx.T(r, "error.host_only"), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// withAdmin requires the admin token (see requireAdmin)
+func (ctx *Context) withAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ctx.requireAdmin(w, r) {
+			next(w, r)
+		}
+	}
+}
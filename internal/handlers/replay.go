@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// setupSummary is the outcome of a game's random setup
+type setupSummary struct {
+	Location      string            `json:"location"`
+	Spies         []string          `json:"spies"`
+	Challenges    map[string]string `json:"challenges,omitempty"`
+	QuestionOrder []string          `json:"question_order"`
+}
+
+type adminReplay struct {
+	Seed     int64        `json:"seed"`
+	Recorded setupSummary `json:"recorded"`
+	Replayed setupSummary `json:"replayed"`
+	Matches  bool         `json:"matches"`
+}
+
+// HandleAdminReplay re-runs the current game's setup from its seed and the lobby
+// state it recorded, and shows it next to what the game actually got. A
+// different ?seed= shows what that seed would have produced instead.
+func (ctx *Context) HandleAdminReplay(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+
+	lobby.RLock()
+	g := lobby.CurrentGame
+	var recorded *models.GameSetup
+	if g != nil {
+		recorded = drawnSetup(g)
+	}
+	if recorded == nil {
+		lobby.RUnlock()
+		http.Error(w, "No game has been set up in this lobby", http.StatusNotFound)
+		return
+	}
+	if g.Setup == nil {
+		// Games restored from before setups were recorded can't be replayed
+		lobby.RUnlock()
+		ctx.Error(w, r, ctx.T(r, "error.setup_not_recorded"), http.StatusConflict)
+		return
+	}
+	seed := g.Seed
+	if s := r.URL.Query().Get("seed"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			lobby.RUnlock()
+			http.Error(w, "Invalid seed", http.StatusBadRequest)
+			return
+		}
+		seed = n
+	}
+	replay := adminReplay{
+		Seed:     seed,
+		Recorded: summarizeSetup(recorded),
+		Replayed: summarizeSetup(drawnSetup(ctx.replaySetup(g, seed))),
+	}
+	lobby.RUnlock()
+
+	replay.Matches = replay.Recorded.equal(replay.Replayed)
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(replay)
+}
+
+type adminEvents struct {
+	Events     []models.GameEvent `json:"events"`
+	Consistent bool               `json:"consistent"`      // folding the log gives the live game
+	State      *models.Game       `json:"state,omitempty"` // the game after ?upto= events
+}
+
+// HandleAdminEvents returns the current game's event log and whether it still
+// adds up to the live game. ?upto=<n> also shows the game as it was after the
+// first n events.
+func (ctx *Context) HandleAdminEvents(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+
+	lobby.RLock()
+	g := lobby.CurrentGame
+	if g == nil {
+		lobby.RUnlock()
+		http.Error(w, "No game is running in this lobby", http.StatusNotFound)
+		return
+	}
+	events := slices.Clone(g.Log)
+	folded, err := models.FoldGame(events)
+	consistent := err == nil && reflect.DeepEqual(folded, g)
+	lobby.RUnlock()
+
+	audit := adminEvents{Events: events, Consistent: consistent}
+	if s := r.URL.Query().Get("upto"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > len(events) {
+			http.Error(w, "Invalid event count", http.StatusBadRequest)
+			return
+		}
+		state, err := models.FoldGame(events[:n])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		audit.State = state
+		audit.State.Log = nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(audit)
+}
+
+// replaySetup runs g's setup again with seed on a copy of the lobby state it
+// recorded, leaving g and the real lobby untouched. g.Setup must be set. (lock must be held)
+func (ctx *Context) replaySetup(g *models.Game, seed int64) *models.Game {
+	in := g.Setup
+	lobby := &models.Lobby{
+		Players:  make(map[string]*models.Player, len(in.Players)),
+		Language: in.Language,
+		History:  in.History.Clone(),
+	}
+	for id, name := range in.Players {
+		lobby.Players[id] = &models.Player{ID: id, Name: name}
+	}
+	if in.SeriesTurns != nil {
+		lobby.Series = &models.Series{SpyTurns: maps.Clone(in.SeriesTurns)}
+	}
+
+	replayed := models.NewGame(g.Settings, seed)
+	for _, id := range slices.Sorted(maps.Keys(g.CustomWords)) {
+		replayed.Record(models.GameEvent{Type: models.EventWordSubmitted, Player: id, Word: g.CustomWords[id]})
+	}
+	ctx.setupGame(lobby, replayed)
+	return replayed
+}
+
+// drawnSetup returns the setup g's log recorded, or nil if g hasn't been set
+// up. Unlike the live game it still names the players who left since.
+func drawnSetup(g *models.Game) *models.GameSetup {
+	for _, e := range slices.Backward(g.Log) {
+		if e.Type == models.EventSetupDrawn {
+			return e.Setup
+		}
+	}
+	return nil
+}
+
+// summarizeSetup pulls the random choices out of setup
+func summarizeSetup(setup *models.GameSetup) setupSummary {
+	s := setupSummary{
+		Spies:         slices.Sorted(maps.Keys(setup.Spies)),
+		Challenges:    make(map[string]string),
+		QuestionOrder: setup.QuestionOrder,
+	}
+	if setup.Location != nil {
+		s.Location = setup.Location.Word
+	}
+	for id, challenge := range setup.Challenges {
+		if challenge != "" {
+			s.Challenges[id] = challenge
+		}
+	}
+	return s
+}
+
+func (s setupSummary) equal(o setupSummary) bool {
+	return s.Location == o.Location &&
+		slices.Equal(s.Spies, o.Spies) &&
+		maps.Equal(s.Challenges, o.Challenges) &&
+		slices.Equal(s.QuestionOrder, o.QuestionOrder)
+}
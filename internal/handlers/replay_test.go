@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/config"
+	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/render"
+	"github.com/aaronzipp/you-are-officially-sus/internal/store"
+)
+
+// testContext returns a context with just enough content to set up games
+func testContext() *Context {
+	return &Context{
+		Config: &config.Config{Game: config.GameConfig{SpySelection: game.SpySelectionWeighted}},
+		Locations: map[string][]models.Location{"en": {
+			{Word: "bank", Categories: []string{"work"}},
+			{Word: "beach", Categories: []string{"outdoors"}},
+			{Word: "hospital", Categories: []string{"work"}},
+			{Word: "library", Categories: []string{"indoors"}},
+			{Word: "zoo", Categories: []string{"outdoors"}},
+		}},
+		Challenges: map[string][]string{"en": {"hum", "whisper", "wink", "yawn", "shrug"}},
+	}
+}
+
+// withPages adds the checkout's catalogs and templates to ctx, so handlers can
+// render pages and errors
+func withPages(t *testing.T, ctx *Context) *Context {
+	t.Helper()
+	catalog, err := i18n.Load(os.DirFS("../../data/locales"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := render.NewTemplates(os.DirFS("../../templates"), false, "", catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.I18n, ctx.Templates = catalog, templates
+	return ctx
+}
+
+// testLobby returns a lobby with the named players, their IDs being their names
+func testLobby(names ...string) *models.Lobby {
+	lobby := &models.Lobby{Players: make(map[string]*models.Player), Language: "en"}
+	for _, name := range names {
+		lobby.Players[name] = &models.Player{ID: name, Name: name}
+	}
+	return lobby
+}
+
+// setUp draws a standard game's setup with seed for the lobby
+func setUp(ctx *Context, lobby *models.Lobby, seed int64) *models.Game {
+	g := models.NewGame(models.LobbySettings{SpyCount: 2}.Normalized(3), seed)
+	ctx.setupGame(lobby, g)
+	return g
+}
+
+func TestSetupIsDeterministic(t *testing.T) {
+	ctx := testContext()
+	players := []string{"ann", "ben", "cat", "dan", "eve"}
+
+	for seed := range int64(20) {
+		a := summarizeSetup(drawnSetup(setUp(ctx, testLobby(players...), seed)))
+		b := summarizeSetup(drawnSetup(setUp(ctx, testLobby(players...), seed)))
+		if !a.equal(b) {
+			t.Errorf("seed %d: setups differ: %+v vs %+v", seed, a, b)
+		}
+		if len(a.Spies) != 2 || a.Location == "" {
+			t.Errorf("seed %d: incomplete setup %+v", seed, a)
+		}
+	}
+}
+
+func TestReplayMatchesAfterPlayerLeft(t *testing.T) {
+	ctx := testContext()
+	g := setUp(ctx, testLobby("ann", "ben", "cat", "dan", "eve"), 7)
+	for id := range g.PlayerInfo {
+		if !g.IsSpy(id) {
+			g.Record(models.GameEvent{Type: models.EventPlayerLeft, Player: id})
+			break
+		}
+	}
+
+	recorded := summarizeSetup(drawnSetup(g))
+	replayed := summarizeSetup(drawnSetup(ctx.replaySetup(g, g.Seed)))
+	if !recorded.equal(replayed) {
+		t.Errorf("replay doesn't match the recorded setup: %+v vs %+v", recorded, replayed)
+	}
+}
+
+func TestReplayOfMigratedGameIsRefused(t *testing.T) {
+	ctx := withPages(t, testContext())
+	for _, fixture := range []string{"snapshot_v1.json", "snapshot_v2.json", "snapshot_v3.json"} {
+		lobbies := store.NewLobbyStore()
+		if _, err := lobbies.LoadSnapshot("../store/testdata/"+fixture, 3); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, lobby := range lobbies.All() {
+			if lobby.CurrentGame == nil {
+				continue
+			}
+			r := httptest.NewRequest(http.MethodGet, "/admin/replay/"+lobby.Code, nil)
+			r = r.WithContext(context.WithValue(r.Context(), lobbyKey, lobby))
+			w := httptest.NewRecorder()
+			ctx.HandleAdminReplay(w, r)
+
+			if w.Code != http.StatusConflict {
+				t.Errorf("%s: status %d, want %d", lobby.Code, w.Code, http.StatusConflict)
+			}
+			if want := ctx.I18n.T("en", "error.setup_not_recorded"); !strings.Contains(w.Body.String(), want) {
+				t.Errorf("%s: response doesn't explain why: %s", lobby.Code, w.Body.String())
+			}
+		}
+	}
+}
@@ -2,150 +2,297 @@ package handlers
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 	"github.com/aaronzipp/you-are-officially-sus/internal/render"
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
 )
 
 // HandleResults displays the game results
 func (ctx *Context) HandleResults(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/results/"), "/")
-	if len(parts) < 1 || len(parts) > 2 {
-		http.Error(w, "Invalid URL", http.StatusBadRequest)
-		return
-	}
-	roomCode := parts[0]
-
-	var playerID string
-	if len(parts) == 2 {
-		// legacy style with player in path
-		playerID = parts[1]
-	} else {
-		_, pid, err := ctx.getLobbyAndPlayer(r, roomCode)
-		if err != nil {
-			http.Redirect(w, r, "/", http.StatusSeeOther)
-			return
-		}
-		playerID = pid
-	}
-
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	roomCode := lobby.Code
 
 	lobby.RLock()
 	defer lobby.RUnlock()
 
 	if lobby.CurrentGame == nil {
-		http.Redirect(w, r, "/lobby/"+roomCode, http.StatusSeeOther)
+		http.Redirect(w, r, ctx.Config.Path("/lobby/"+roomCode), http.StatusSeeOther)
 		return
 	}
 
 	currentGame := lobby.CurrentGame
 	if currentGame.Status != models.StatusFinished {
-		http.Redirect(w, r, game.PhasePathFor(roomCode, currentGame.Status), http.StatusSeeOther)
+		http.Redirect(w, r, ctx.Config.Path(game.PhasePathFor(roomCode, currentGame.Status)), http.StatusSeeOther)
 		return
 	}
 
-	// Calculate vote counts
-	voteCount := make(map[string]int)
-	for _, suspectID := range currentGame.Votes {
-		voteCount[suspectID]++
-	}
-
-	// Find most voted and check for tie
-	var mostVoted string
-	maxVotes := 0
-	isTie := false
-
-	// Handle spy forfeit case
-	if currentGame.SpyForfeited {
-		// Spy forfeited - innocents win by default
-		mostVoted = currentGame.SpyID
-		isTie = false
-	} else {
-		voteCounts := make(map[int]int) // count -> frequency
-		for _, count := range voteCount {
-			voteCounts[count]++
-			if count > maxVotes {
-				maxVotes = count
-			}
+	tally := currentGame.Tally
+	votes := buildVotesView(lobby, currentGame, tally)
+
+	// Spies are kept by name on the game so ones who left can still be shown
+	spies := make([]spyView, 0, len(currentGame.Spies))
+	isSpy := make(map[string]bool)
+	for id, name := range currentGame.Spies {
+		_, present := lobby.Players[id]
+		spies = append(spies, spyView{ID: id, Name: name, Left: !present})
+		isSpy[id] = true
+	}
+	slices.SortFunc(spies, func(a, b spyView) int { return strings.Compare(a.Name, b.Name) })
+
+	data := struct {
+		RoomCode        string
+		PlayerID        string
+		IsHost          bool
+		CanHost         bool
+		Players         []*models.Player
+		Spies           []spyView
+		IsSpy           map[string]bool
+		Location        *models.Location
+		AnonymousVoting bool
+		VotingSystem    models.VotingSystem
+		VoteCount       map[string]int
+		Votes           votesView
+		VoteRounds      int
+		MostVoted       string
+		IsTie           bool
+		InnocentWon     bool
+		SpyForfeited    bool
+		Guess           *guessView
+		Reviews         challengeReviewViewData
+		Series          *seriesViewData
+	}{
+		RoomCode:        roomCode,
+		PlayerID:        playerID,
+		IsHost:          lobby.Host == playerID,
+		CanHost:         lobby.CanHost(playerID),
+		Players:         render.GetPlayerList(lobby.Players),
+		Spies:           spies,
+		IsSpy:           isSpy,
+		Location:        currentGame.Location,
+		AnonymousVoting: currentGame.Settings.AnonymousVoting,
+		VotingSystem:    currentGame.Settings.VotingSystem,
+		VoteCount:       tally.VoteCount,
+		Votes:           votes,
+		VoteRounds:      currentGame.VoteRound,
+		MostVoted:       tally.MostVoted,
+		IsTie:           tally.IsTie,
+		InnocentWon:     currentGame.InnocentWon,
+		SpyForfeited:    currentGame.SpyForfeited,
+		Guess:           buildGuessView(currentGame),
+		Reviews:         ctx.buildChallengeReviewData(lobby, playerID),
+		Series:          ctx.buildSeriesData(lobby),
+	}
+
+	ctx.render(w, r, "results.html", data)
+}
+
+// votesView is the final voting round on the results page
+type votesView struct {
+	Ballots    []ballotView
+	Eliminated []string   // names, ranked voting only
+	Trial      *trialView // accusation voting, or a live accusation that ended the game
+}
+
+// ballotView is one player's final ballot, by name
+type ballotView struct {
+	Voter    string
+	Suspects []string
+	Correct  bool
+}
+
+// trialView is the trial of the final round, or the accusation that stopped play
+type trialView struct {
+	Accused   string
+	Accuser   string // set for a live accusation
+	Acquitted bool
+	Guilty    []string // names of the players who voted guilty
+	NotGuilty []string
+}
+
+// buildVotesView names the voters and suspects of g's final round. Players who
+// left are named from the game's setup. (lock must be held)
+func buildVotesView(lobby *models.Lobby, g *models.Game, tally *models.VoteResult) votesView {
+	name := func(id string) string {
+		if p, ok := lobby.Players[id]; ok {
+			return p.Name
+		}
+		if g.Setup != nil {
+			return g.Setup.Players[id]
+		}
+		return ""
+	}
+
+	var v votesView
+	for voter, ballot := range g.Votes {
+		b := ballotView{Voter: name(voter), Correct: tally.VotedCorrectly[voter]}
+		for _, suspect := range ballot {
+			b.Suspects = append(b.Suspects, name(suspect))
+		}
+		v.Ballots = append(v.Ballots, b)
+	}
+	slices.SortFunc(v.Ballots, func(a, b ballotView) int { return strings.Compare(a.Voter, b.Voter) })
+
+	for _, id := range tally.Eliminated {
+		v.Eliminated = append(v.Eliminated, name(id))
+	}
+
+	if t := g.Trial; t != nil {
+		v.Trial = &trialView{Accused: name(t.Accused), Acquitted: tally.Acquitted}
+		if t.Live() {
+			v.Trial.Accuser = name(t.Accuser)
 		}
-		if voteCounts[maxVotes] > 1 {
-			isTie = true
-		} else {
-			for suspectID, count := range voteCount {
-				if count == maxVotes {
-					mostVoted = suspectID
-					break
-				}
+		for voter, guilty := range t.Verdicts {
+			if guilty {
+				v.Trial.Guilty = append(v.Trial.Guilty, name(voter))
+			} else {
+				v.Trial.NotGuilty = append(v.Trial.NotGuilty, name(voter))
 			}
 		}
+		slices.Sort(v.Trial.Guilty)
+		slices.Sort(v.Trial.NotGuilty)
 	}
+	return v
+}
+
+// spyView is one spy on the results page
+type spyView struct {
+	ID   string
+	Name string
+	Left bool // left the lobby during the game
+}
 
-	innocentWon := !isTie && mostVoted == currentGame.SpyID
+// guessView is a spy's guess of the location that ended play
+type guessView struct {
+	Spy     string
+	Word    string
+	Correct bool
+}
 
-	// Build challenges map
-	challengesMap := make(map[string]string)
-	for pid, info := range currentGame.PlayerInfo {
-		challengesMap[pid] = info.Challenge
+// buildGuessView returns the guess that ended g, or nil if no spy guessed
+func buildGuessView(g *models.Game) *guessView {
+	guess := g.SpyGuess
+	if guess == nil {
+		return nil
 	}
+	return &guessView{Spy: g.Spies[guess.Spy], Word: guess.Word, Correct: guess.Correct}
+}
+
+// HandleChallengeReview records whether the player thinks another player
+// completed their challenge. Verdicts can change the challenge bonus, so the
+// game is rescored and everyone's challenge and points cards are refreshed.
+func (ctx *Context) HandleChallengeReview(w http.ResponseWriter, r *http.Request) {
+	lobby, playerID := lobbyFrom(r), playerFrom(r)
+	ownerID := r.FormValue("player")
+	completed := r.FormValue("completed") == "yes"
 
-	// Build voted correctly map
-	votedCorrectly := make(map[string]bool)
-	for voterID, suspectID := range currentGame.Votes {
-		votedCorrectly[voterID] = suspectID == currentGame.SpyID
+	lobby.Lock()
+	g := lobby.CurrentGame
+	if g == nil || g.Status != models.StatusFinished || g.Settings.NoChallenges {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.no_challenge_review"), http.StatusBadRequest)
+		return
+	}
+	info, ok := g.PlayerInfo[ownerID]
+	if _, present := lobby.Players[ownerID]; !present || !ok || info.Challenge == "" || ownerID == playerID {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.invalid_challenge_review"), http.StatusBadRequest)
+		return
 	}
+	g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: playerID, Target: ownerID, Completed: completed})
+	ctx.rescoreGame(lobby, g)
+	verdict := g.ChallengeVerdict(ownerID, lobby.PlayerIDs())
+	lang := lobby.Language
+	lobby.Unlock()
+
+	logging.FromContext(r.Context()).Info("Challenge reviewed", "owner", ownerID, "completed", completed, "verdict", verdict)
+
+	sse.BroadcastPersonalized(lobby, func(pid string) string {
+		return ctx.ChallengeReviews(lobby, pid)
+	}, sse.EventChallenges)
+	sse.Broadcast(lobby, sse.EventPlayerUpdate, ctx.PlayerList(lang, lobby))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// challengeReviewViewData backs the results page's challenge and points cards
+type challengeReviewViewData struct {
+	RoomCode       string
+	ShowChallenges bool
+	BonusPoints    bool // confirmed challenges earn points
+	Challenges     []challengeView
+	Players        []*models.Player
+	Awards         map[string][]models.PointAward
+	PointsEarned   map[string]int
+}
 
-	// Get spy info - handle case where spy left
-	var spy *models.Player
-	if currentGame.SpyForfeited {
-		// Create a temporary player object for the spy who left
-		spy = &models.Player{
-			ID:   currentGame.SpyID,
-			Name: currentGame.SpyName,
+// challengeView is one player's challenge as seen by the viewer
+type challengeView struct {
+	PlayerID  string
+	Name      string
+	Challenge string
+	Own       bool   // the viewer's own challenge
+	MyReview  string // "yes", "no" or "" if the viewer hasn't reviewed it
+	Confirms  int
+	Disputes  int
+	Verdict   models.ChallengeVerdict
+}
+
+// buildChallengeReviewData prepares the challenge and points cards for viewerID (lock must be held)
+func (ctx *Context) buildChallengeReviewData(lobby *models.Lobby, viewerID string) challengeReviewViewData {
+	g := lobby.CurrentGame
+	players := render.GetPlayerList(lobby.Players)
+	playerIDs := lobby.PlayerIDs()
+
+	data := challengeReviewViewData{
+		RoomCode:       lobby.Code,
+		ShowChallenges: !g.Settings.NoChallenges,
+		BonusPoints:    !g.Settings.NoChallengeBonus,
+		Players:        players,
+		Awards:         g.Awards,
+		PointsEarned:   make(map[string]int),
+	}
+	for id, awards := range g.Awards {
+		data.PointsEarned[id] = models.TotalPoints(awards)
+	}
+	if !data.ShowChallenges {
+		return data
+	}
+
+	for _, p := range players {
+		info, ok := g.PlayerInfo[p.ID]
+		if !ok || info.Challenge == "" {
+			continue
 		}
-	} else {
-		spy = lobby.Players[currentGame.SpyID]
+		view := challengeView{
+			PlayerID:  p.ID,
+			Name:      p.Name,
+			Challenge: info.Challenge,
+			Own:       p.ID == viewerID,
+			Verdict:   g.ChallengeVerdict(p.ID, playerIDs),
+		}
+		view.Confirms, view.Disputes = g.ChallengeTally(p.ID, playerIDs)
+		if completed, reviewed := g.ChallengeReviews[p.ID][viewerID]; reviewed {
+			view.MyReview = "no"
+			if completed {
+				view.MyReview = "yes"
+			}
+		}
+		data.Challenges = append(data.Challenges, view)
 	}
+	return data
+}
 
-	data := struct {
-		RoomCode       string
-		PlayerID       string
-		IsHost         bool
-		Players        []*models.Player
-		Spy            *models.Player
-		Location       *models.Location
-		Challenges     map[string]string
-		Votes          map[string]string
-		VoteCount      map[string]int
-		VotedCorrectly map[string]bool
-		VoteRounds     int
-		MostVoted      string
-		IsTie          bool
-		InnocentWon    bool
-		SpyForfeited   bool
-	}{
-		RoomCode:       roomCode,
-		PlayerID:       playerID,
-		IsHost:         lobby.Host == playerID,
-		Players:        render.GetPlayerList(lobby.Players),
-		Spy:            spy,
-		Location:       currentGame.Location,
-		Challenges:     challengesMap,
-		Votes:          currentGame.Votes,
-		VoteCount:      voteCount,
-		VotedCorrectly: votedCorrectly,
-		VoteRounds:     currentGame.VoteRound,
-		MostVoted:      mostVoted,
-		IsTie:          isTie,
-		InnocentWon:    innocentWon,
-		SpyForfeited:   currentGame.SpyForfeited,
-	}
-
-	ctx.Templates.ExecuteTemplate(w, "results.html", data)
+// ChallengeReviews renders the results page's challenge and points cards for playerID
+func (ctx *Context) ChallengeReviews(lobby *models.Lobby, playerID string) string {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	if lobby.CurrentGame == nil || lobby.CurrentGame.Status != models.StatusFinished {
+		return ""
+	}
+	return ctx.ExecutePartial(lobby.Language, "challenge_reviews.html", ctx.buildChallengeReviewData(lobby, playerID))
 }
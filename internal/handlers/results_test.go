@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/scoring"
+)
+
+func TestRescoreGameMovesReviewPointsIntoScores(t *testing.T) {
+	ctx := &Context{Scoring: scoring.Default()}
+	lobby := testLobby("ann", "ben", "cat", "eve")
+	lobby.Scores = make(map[string]*models.PlayerScore)
+	for id := range lobby.Players {
+		lobby.Scores[id] = &models.PlayerScore{}
+	}
+
+	g := models.NewGame(models.LobbySettings{}.Normalized(3), 1)
+	g.Record(models.GameEvent{Type: models.EventSetupDrawn, Setup: &models.GameSetup{
+		Spies:      map[string]string{"eve": "eve"},
+		Challenges: map[string]string{"ann": "hum", "ben": "wink", "cat": "yawn", "eve": "shrug"},
+	}})
+	g.Record(models.GameEvent{Type: models.EventPhaseChanged, Status: models.StatusFinished})
+	g.Record(models.GameEvent{Type: models.EventGameFinished, InnocentWon: true, Tally: &models.VoteResult{
+		MostVoted: "eve", InnocentWon: true, VotedCorrectly: map[string]bool{"ann": true, "ben": true},
+	}})
+	g.Record(models.GameEvent{Type: models.EventPointsAwarded, Awards: ctx.Scoring.Score(ctx.outcome(lobby, g))})
+	for id, awards := range g.Awards {
+		lobby.Scores[id].Points += models.TotalPoints(awards)
+	}
+	before := make(map[string]int)
+	for id, score := range lobby.Scores {
+		before[id] = score.Points
+	}
+
+	for _, reviewer := range []string{"ann", "ben"} {
+		g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: reviewer, Target: "cat", Completed: true})
+		ctx.rescoreGame(lobby, g)
+	}
+
+	for id, score := range lobby.Scores {
+		want := before[id]
+		if id == "cat" {
+			want += 2
+		}
+		if score.Points != want {
+			t.Errorf("%s has %d points, want %d", id, score.Points, want)
+		}
+		if got := models.TotalPoints(g.Awards[id]); got != score.Points {
+			t.Errorf("%s's awards total %d, but their score is %d", id, got, score.Points)
+		}
+	}
+}
+
+func TestFinishGameCountsVerdictsAsVotes(t *testing.T) {
+	ctx := &Context{Scoring: scoring.Default()}
+	lobby := testLobby("ann", "ben", "cat", "eve")
+	lobby.Scores = make(map[string]*models.PlayerScore)
+	for id, p := range lobby.Players {
+		p.AccountID = "acct-" + id
+		lobby.Scores[id] = &models.PlayerScore{}
+	}
+
+	// ann stops play to accuse eve, and the others agree
+	g := models.NewGame(models.LobbySettings{}.Normalized(3), 1)
+	g.Record(models.GameEvent{Type: models.EventSetupDrawn, Setup: &models.GameSetup{
+		Spies:      map[string]string{"eve": "eve"},
+		Challenges: map[string]string{"ann": "hum", "ben": "wink", "cat": "yawn", "eve": "shrug"},
+	}})
+	g.Record(models.GameEvent{Type: models.EventPhaseChanged, Status: models.StatusPlaying})
+	g.Record(models.GameEvent{Type: models.EventAccusationMade, Player: "ann", Target: "eve"})
+	g.Record(models.GameEvent{Type: models.EventVerdictCast, Player: "ben", Guilty: true})
+	g.Record(models.GameEvent{Type: models.EventVerdictCast, Player: "cat", Guilty: true})
+
+	results := ctx.finishGame(lobby, g, true)
+	for _, id := range []string{"ann", "ben", "cat"} {
+		if r := results["acct-"+id]; !r.Voted || !r.VotedCorrectly {
+			t.Errorf("%s's result = %+v, want a correct vote", id, r)
+		}
+	}
+	if r := results["acct-eve"]; r.Voted || !r.WasSpy || r.Won {
+		t.Errorf("eve's result = %+v, want a caught spy who didn't vote", r)
+	}
+}
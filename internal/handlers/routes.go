@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/metrics"
+)
+
+// gamePhasePages are the per-phase pages under /game/{code}/
+var gamePhasePages = []string{"word-collection", "confirm-reveal", "roles", "play", "voting"}
+
+// Routes registers all application routes on mux, each instrumented with request metrics
+func (ctx *Context) Routes(mux *http.ServeMux) {
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, metrics.InstrumentRoute(pattern, h))
+	}
+
+	handle("GET /{$}", ctx.HandleIndex)
+	handle("POST /create", ctx.HandleCreateLobby)
+	handle("POST /join", ctx.HandleJoinLobby)
+	handle("GET /join/{code}", ctx.withLobby(ctx.HandleJoinLobbyScreen))
+	handle("POST /join/{code}", ctx.HandleJoinLobby)
+	handle("GET /lobby/{code}", ctx.withMember(ctx.HandleLobby))
+	handle("POST /lobby/{code}/language", ctx.withHost(ctx.HandleSetLanguage))
+	handle("POST /lobby/{code}/settings", ctx.withHost(ctx.HandleUpdateSettings))
+	handle("POST /lobby/{code}/co-hosts", ctx.withHost(ctx.HandleSetCoHost))
+	handle("POST /lobby/{code}/host-vote", ctx.withMember(ctx.HandleHostVote))
+	handle("GET /lobby/{code}/history", ctx.withMember(ctx.HandleHistory))
+	handle("GET /lobby/{code}/history.json", ctx.withMember(ctx.HandleHistoryDownload))
+	handle("GET /sse/{code}", ctx.HandleSSE)
+
+	// Accounts and stats
+	handle("GET /stats", ctx.HandleStats)
+	handle("POST /stats/account", ctx.HandleCreateAccount)
+	handle("GET /stats/sign-in", ctx.HandleAccountSignIn)
+	handle("POST /stats/sign-out", ctx.HandleAccountSignOut)
+
+	// Game phases (GET) and actions (POST)
+	handle("GET /game/{code}", ctx.withMember(ctx.HandleGamePage))
+	for _, page := range gamePhasePages {
+		handle("GET /game/{code}/"+page, ctx.withMember(ctx.HandleGamePage))
+	}
+	handle("GET /game/{code}/redirect", ctx.HandleGameRedirect)
+	handle("POST /game/{code}/ready", ctx.withMember(ctx.HandleReady))
+	handle("POST /game/{code}/vote", ctx.withMember(ctx.HandleVote))
+	handle("POST /game/{code}/verdict", ctx.withMember(ctx.HandleVerdict))
+	handle("POST /game/{code}/accuse", ctx.withMember(ctx.HandleAccuse))
+	handle("POST /game/{code}/guess", ctx.withMember(ctx.HandleGuessLocation))
+	handle("POST /game/{code}/submit-word", ctx.withMember(ctx.HandleSubmitWord))
+	handle("GET /results/{code}", ctx.withMember(ctx.HandleResults))
+	handle("POST /results/{code}/challenge-review", ctx.withMember(ctx.HandleChallengeReview))
+
+	// Lobby/game lifecycle
+	handle("POST /start-game/{code}", ctx.withHost(ctx.HandleStartGame))
+	handle("POST /start-series/{code}", ctx.withHost(ctx.HandleStartSeries))
+	handle("POST /next-round/{code}", ctx.withHost(ctx.HandleNextRound))
+	handle("POST /restart-game/{code}", ctx.withHost(ctx.HandleRestartGame))
+	handle("POST /close-lobby/{code}", ctx.withHost(ctx.HandleCloseLobby))
+	handle("POST /leave-lobby/{code}", ctx.withMember(ctx.HandleLeaveLobby))
+	handle("GET /select-host/{code}", ctx.withHost(ctx.HandleSelectHost))
+	handle("POST /leave-lobby-with-host/{code}", ctx.withHost(ctx.HandleLeaveLobbyWithHost))
+
+	// Health checks and admin status
+	handle("GET /healthz", ctx.HandleHealthz)
+	handle("GET /readyz", ctx.HandleReadyz)
+	handle("GET /admin", ctx.withAdmin(ctx.HandleAdmin))
+	handle("POST /admin/close-lobby/{code}", ctx.withAdmin(ctx.withLobby(ctx.HandleAdminCloseLobby)))
+	handle("GET /admin/replay/{code}", ctx.withAdmin(ctx.withLobby(ctx.HandleAdminReplay)))
+	handle("GET /admin/events/{code}", ctx.withAdmin(ctx.withLobby(ctx.HandleAdminEvents)))
+
+	// Anything unmatched gets the styled 404 page
+	handle("/", ctx.HandleNotFound)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/render"
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
+)
+
+// HandleStartSeries starts a series of games. Each game starts automatically a
+// little while after the previous one's results, and the series is long enough
+// for every player to be a spy at least once.
+func (ctx *Context) HandleStartSeries(w http.ResponseWriter, r *http.Request) {
+	logger := logging.FromContext(r.Context())
+	lobby := lobbyFrom(r)
+	roomCode := lobby.Code
+
+	lobby.Lock()
+	if key, args := ctx.startGameError(lobby); key != "" {
+		lobby.Unlock()
+		logger.Info("Start series refused", "reason", key, "players", len(lobby.Players))
+		ctx.Error(w, r, ctx.T(r, key, args...), http.StatusBadRequest)
+		return
+	}
+	minRounds, maxRounds := ctx.seriesRoundLimits(lobby)
+	rounds, err := strconv.Atoi(r.FormValue("rounds"))
+	if err != nil || rounds < minRounds || rounds > maxRounds {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.series_rounds", minRounds, maxRounds), http.StatusBadRequest)
+		return
+	}
+	lobby.Series = models.NewSeries(rounds, lobby.Scores)
+	redirectPath := ctx.startGame(lobby)
+	lobby.Unlock()
+
+	logger.Info("Series started", "rounds", rounds, "path", redirectPath)
+
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(roomCode, redirectPath))
+
+	w.Header().Set("HX-Redirect", ctx.Config.Path(redirectPath))
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleNextRound skips the wait between series games
+func (ctx *Context) HandleNextRound(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+
+	lobby.Lock()
+	redirectPath := ctx.startNextRound(lobby, lobby.CurrentGame)
+	lobby.Unlock()
+
+	if redirectPath == "" {
+		ctx.Error(w, r, ctx.T(r, "error.no_next_round"), http.StatusBadRequest)
+		return
+	}
+	logging.FromContext(r.Context()).Info("Next series round started early", "path", redirectPath)
+
+	sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(lobby.Code, redirectPath))
+
+	w.Header().Set("HX-Redirect", ctx.Config.Path(redirectPath))
+	w.WriteHeader(http.StatusOK)
+}
+
+// autoNextRound starts the series' next game once the break after finished is
+// over, unless the host already moved on
+func (ctx *Context) autoNextRound(lobby *models.Lobby, finished *models.Game) {
+	if current, ok := ctx.LobbyStore.Get(lobby.Code); !ok || current != lobby {
+		return // lobby closed in the meantime
+	}
+
+	lobby.Lock()
+	redirectPath := ctx.startNextRound(lobby, finished)
+	lobby.Unlock()
+
+	if redirectPath != "" {
+		sse.Broadcast(lobby, sse.EventNavRedirect, ctx.RedirectSnippet(lobby.Code, redirectPath))
+	}
+}
+
+// startNextRound replaces finished with the series' next game and returns the
+// path to send everyone to. If too many players left to go on, the series ends
+// and everyone returns to the lobby. Returns "" when finished is no longer the
+// lobby's game or the series is over. (lock must be held)
+func (ctx *Context) startNextRound(lobby *models.Lobby, finished *models.Game) string {
+	s := lobby.Series
+	if s == nil || s.IsLastRound() || finished == nil || lobby.CurrentGame != finished || finished.Status != models.StatusFinished {
+		return ""
+	}
+
+	lobby.EndCurrentGame()
+	if key, _ := ctx.startGameError(lobby); key != "" {
+		lobby.Series = nil
+		return "/lobby/" + lobby.Code
+	}
+	return ctx.startGame(lobby)
+}
+
+// seriesRoundLimits returns the allowed series lengths for the lobby: long
+// enough for every player to be a spy once (lock must be held)
+func (ctx *Context) seriesRoundLimits(lobby *models.Lobby) (int, int) {
+	settings := lobby.Settings.Normalized(ctx.Config.Game.MaxVoteRounds)
+	minRounds := models.MinRounds(len(lobby.Players), settings.SpyCount)
+	return minRounds, max(minRounds, models.MaxSeriesRounds)
+}
+
+// seriesViewData backs the series card on the results page
+type seriesViewData struct {
+	Round        int
+	Rounds       int
+	Last         bool
+	BreakSeconds int
+	Winners      []string // names; set after the last game
+	Standings    playerListViewData
+}
+
+// buildSeriesData prepares the series card, or nil outside a series (lock must be held)
+func (ctx *Context) buildSeriesData(lobby *models.Lobby) *seriesViewData {
+	s := lobby.Series
+	if s == nil {
+		return nil
+	}
+	standings := s.Standings(lobby.Scores)
+	data := &seriesViewData{
+		Round:        s.Round,
+		Rounds:       s.Rounds,
+		Last:         s.IsLastRound(),
+		BreakSeconds: game.SeriesBreakSeconds,
+		Standings: playerListViewData{
+			Players:    render.GetPlayerListSortedByScore(lobby.Players, standings),
+			Scores:     standings,
+			HasResults: true,
+			HostID:     lobby.Host,
+			CoHosts:    lobby.CoHosts,
+		},
+	}
+	if data.Last {
+		for _, id := range s.Winners(lobby.Scores) {
+			if p, ok := lobby.Players[id]; ok {
+				data.Winners = append(data.Winners, p.Name)
+			}
+		}
+	}
+	return data
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
+)
+
+// HandleUpdateSettings saves the host's settings panel. The panel posts the
+// whole form on every change; everyone's lobby card is re-rendered with the result.
+func (ctx *Context) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	lobby := lobbyFrom(r)
+
+	if err := r.ParseForm(); err != nil {
+		ctx.Error(w, r, ctx.T(r, "error.invalid_settings"), http.StatusBadRequest)
+		return
+	}
+	lobby.RLock()
+	lang := lobby.Language
+	lobby.RUnlock()
+	settings, ok := ctx.parseSettings(r, lang)
+	if !ok {
+		ctx.Error(w, r, ctx.T(r, "error.invalid_settings"), http.StatusBadRequest)
+		return
+	}
+
+	lobby.Lock()
+	if lobby.CurrentGame != nil {
+		lobby.Unlock()
+		ctx.Error(w, r, ctx.T(r, "error.game_in_progress"), http.StatusBadRequest)
+		return
+	}
+	lobby.Settings = settings.Normalized(ctx.Config.Game.MaxVoteRounds)
+	lobby.Unlock()
+
+	logging.FromContext(r.Context()).Info("Lobby settings changed",
+		"mode", settings.Mode, "spies", settings.SpyCount, "minutes", settings.DiscussionMinutes,
+		"categories", len(settings.Categories), "vote_rounds", settings.MaxVoteRounds, "voting", settings.VotingSystem,
+		"accusations", settings.Accusations, "lock_votes", settings.LockVotes)
+
+	sse.BroadcastPersonalized(lobby, func(pid string) string {
+		return ctx.HostControls(lobby, pid)
+	}, sse.EventControlsUpdate)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseSettings reads the settings form. Numbers outside their limits are
+// clamped later; values that are not numbers or unknown categories are rejected.
+func (ctx *Context) parseSettings(r *http.Request, lang string) (models.LobbySettings, bool) {
+	s := models.LobbySettings{
+		Mode:             models.GameMode(r.FormValue("mode")),
+		NoChallenges:     r.FormValue("challenges") == "",
+		AnonymousVoting:  r.FormValue("anonymous_voting") != "",
+		NoChallengeBonus: r.FormValue("challenge_bonus") == "",
+		LockVotes:        r.FormValue("lock_votes") != "",
+		VotingSystem:     models.VotingSystem(r.FormValue("voting_system")),
+	}
+	if s.Mode != models.GameModeStandard && s.Mode != models.GameModeCustomWords {
+		return s, false
+	}
+	if !slices.Contains(models.VotingSystems, s.VotingSystem) {
+		return s, false
+	}
+
+	for field, dst := range map[string]*int{
+		"spy_count":          &s.SpyCount,
+		"discussion_minutes": &s.DiscussionMinutes,
+		"max_vote_rounds":    &s.MaxVoteRounds,
+	} {
+		n, err := strconv.Atoi(r.FormValue(field))
+		if err != nil || n < 1 {
+			return s, false
+		}
+		*dst = n
+	}
+	// No accusations at all is a choice of its own
+	accusations, err := strconv.Atoi(r.FormValue("accusations"))
+	if err != nil || accusations < 0 {
+		return s, false
+	}
+	s.Accusations = accusations
+	s.NoAccusations = accusations == 0
+
+	available := ctx.categories(lang)
+	for _, c := range r.Form["category"] {
+		if !slices.Contains(available, c) {
+			return s, false
+		}
+		s.Categories = append(s.Categories, c)
+	}
+	// Every category ticked is the same as no filter
+	if len(s.Categories) == len(available) {
+		s.Categories = nil
+	}
+	return s, true
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
+)
+
+// shutdownState tracks whether the server is draining before exit
+type shutdownState struct {
+	once     sync.Once
+	draining atomic.Bool
+	done     chan struct{}
+}
+
+// doneChan lazily creates the channel closed when shutdown begins
+func (s *shutdownState) doneChan() chan struct{} {
+	s.once.Do(func() {
+		s.done = make(chan struct{})
+	})
+	return s.done
+}
+
+// Draining reports whether the server has begun shutting down
+func (ctx *Context) Draining() bool {
+	return ctx.shutdown.draining.Load()
+}
+
+// ShutdownDone returns a channel that is closed once shutdown begins.
+// Long-lived handlers (SSE) select on it so http.Server.Shutdown can drain them.
+func (ctx *Context) ShutdownDone() <-chan struct{} {
+	return ctx.shutdown.doneChan()
+}
+
+// ServerRestartingMessage generates HTML for the server restart notice
+func (ctx *Context) ServerRestartingMessage(lang, message string) string {
+	return ctx.ExecutePartial(lang, "server_restarting.html", struct {
+		Message string
+	}{
+		Message: message,
+	})
+}
+
+// BeginShutdown stops accepting new lobbies, notifies every connected client and
+// releases all SSE streams. It is safe to call more than once.
+func (ctx *Context) BeginShutdown() {
+	if !ctx.shutdown.draining.CompareAndSwap(false, true) {
+		return
+	}
+	done := ctx.shutdown.doneChan()
+
+	lobbies := ctx.LobbyStore.All()
+	slog.Info("Notifying lobbies of shutdown", "lobbies", len(lobbies))
+	for _, lobby := range lobbies {
+		lobby.RLock()
+		lang := lobby.Language
+		lobby.RUnlock()
+		notice := ctx.ServerRestartingMessage(lang, ctx.I18n.T(lang, "restart.message"))
+		sse.Broadcast(lobby, sse.EventErrorMessage, notice)
+	}
+
+	close(done)
+}
+
+// rejectIfDraining responds with 503 when the server is shutting down.
+// Returns true if the request was rejected.
+func (ctx *Context) rejectIfDraining(w http.ResponseWriter, r *http.Request) bool {
+	if !ctx.Draining() {
+		return false
+	}
+	w.Header().Set("Retry-After", "10")
+	ctx.Error(w, r, ctx.T(r, "error.shutting_down"), http.StatusServiceUnavailable)
+	return true
+}
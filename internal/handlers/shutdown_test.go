@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDrainingRejectsInTheRequestsLanguage(t *testing.T) {
+	ctx := withPages(t, testContext())
+	ctx.shutdown.draining.Store(true)
+
+	r := httptest.NewRequest(http.MethodPost, "/lobby", nil)
+	r.Header.Set("Accept-Language", "de")
+	w := httptest.NewRecorder()
+	if !ctx.rejectIfDraining(w, r) {
+		t.Fatal("request let through while draining")
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("status %d with Retry-After %q, want 503 with a retry hint", w.Code, w.Header().Get("Retry-After"))
+	}
+	if want := ctx.I18n.T("de", "error.shutting_down"); !strings.Contains(w.Body.String(), want) {
+		t.Errorf("body doesn't say %q:\n%s", want, w.Body.String())
+	}
+}
@@ -2,11 +2,10 @@ package handlers
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 
-	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
 )
@@ -25,57 +24,28 @@ func formatSSEData(data string) string {
 
 // HandleSSE handles Server-Sent Events for real-time updates
 func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
-	if debug {
-		log.Printf("handleSSE called: %s", r.URL.Path)
-	}
+	logger := logging.FromContext(r.Context())
 
-	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/sse/"), "/")
-	if len(parts) < 1 || len(parts) > 2 {
-		if debug {
-			log.Printf("handleSSE: invalid URL parts=%v", parts)
-		}
-		http.Error(w, "Invalid URL", http.StatusBadRequest)
+	// Refuse new streams while draining; htmx will retry once the server is back
+	if ctx.rejectIfDraining(w, r) {
 		return
 	}
-	roomCode := parts[0]
-	var playerID string
-
-	if len(parts) == 2 {
-		// Legacy style: /sse/:room/:player
-		playerID = parts[1]
-	} else {
-		// Cookie-based: /sse/:room
-		lobby, pid, err := ctx.getLobbyAndPlayer(r, roomCode)
-		if err != nil {
-			// Not authorized or lobby validation failed: instruct client to navigate home via HTMX snippet
-			w.Header().Set("Content-Type", "text/event-stream")
-			w.Header().Set("Cache-Control", "no-cache")
-			w.Header().Set("Connection", "keep-alive")
-			fmt.Fprintf(w, "event: %s\n%s\n", sse.EventNavRedirect, formatSSEData(ctx.RedirectSnippet(roomCode, "/")))
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
-			return
-		}
-		_ = lobby // validated but not needed yet
-		playerID = pid
-	}
-
-	if debug {
-		log.Printf("handleSSE: roomCode=%s playerID=%s", roomCode, playerID)
-	}
 
+	// Outsiders and closed lobbies get a nav-redirect home instead of a stream
+	roomCode := r.PathValue("code")
 	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		if debug {
-			log.Printf("handleSSE: room %s not found, sending nav-redirect to home", roomCode)
-		}
-		// Set SSE headers
+	playerID := sessionPlayerID(r)
+	member := false
+	if exists {
+		lobby.RLock()
+		_, member = lobby.Players[playerID]
+		lobby.RUnlock()
+	}
+	if !member {
+		logger.Debug("SSE request for unknown lobby or non-member, sending nav-redirect to home", logging.Room(roomCode))
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
 		w.Header().Set("Connection", "keep-alive")
-
-		// Send nav-redirect snippet for HTMX to navigate home
 		fmt.Fprintf(w, "event: %s\n%s\n", sse.EventNavRedirect, formatSSEData(ctx.RedirectSnippet(roomCode, "/")))
 		if flusher, ok := w.(http.Flusher); ok {
 			flusher.Flush()
@@ -83,9 +53,7 @@ func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if debug {
-		log.Printf("handleSSE: found lobby, setting up SSE for player %s", playerID)
-	}
+	logger = logger.With(logging.Room(roomCode), logging.Player(playerID))
 
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -99,19 +67,18 @@ func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create client channel
-	clientChan := make(chan models.SSEMessage, game.SSEBufferSize)
+	clientChan := make(chan models.SSEMessage, ctx.Config.Game.SSEBufferSize)
 	sse.AddClient(lobby, clientChan, playerID)
 	defer sse.RemoveClient(lobby, clientChan)
 
 	lobby.RLock()
 	clientCount := lobby.SSEClientCount()
 	lobby.RUnlock()
-	if debug {
-		log.Printf("handleSSE: client %s connected, now have %d total clients", playerID, clientCount)
-	}
+	logger.Debug("SSE client connected", "clients", clientCount)
 
 	// Send initial data based on whether a game is in progress
 	lobby.RLock()
+	lang := lobby.Language
 	gameInProgress := lobby.CurrentGame != nil
 	if gameInProgress {
 		// Game in progress - send ready count or vote count with phase-specific event
@@ -129,7 +96,7 @@ func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 			totalPlayers := len(lobby.Players)
-			countHTML = ctx.ReadyCount(readyCount, totalPlayers, "players ready")
+			countHTML = ctx.ReadyCount(lang, readyCount, totalPlayers, "count.ready")
 			eventName = "ready-count-check"
 		case models.StatusRoleReveal:
 			for id := range lobby.Players {
@@ -138,7 +105,7 @@ func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 			totalPlayers := len(lobby.Players)
-			countHTML = ctx.ReadyCount(readyCount, totalPlayers, "players ready")
+			countHTML = ctx.ReadyCount(lang, readyCount, totalPlayers, "count.ready")
 			eventName = "ready-count-reveal"
 		case models.StatusPlaying:
 			for id := range lobby.Players {
@@ -147,28 +114,23 @@ func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 			totalPlayers := len(lobby.Players)
-			countHTML = ctx.ReadyCount(readyCount, totalPlayers, "players ready to vote")
+			countHTML = ctx.ReadyCount(lang, readyCount, totalPlayers, "count.ready_to_vote")
 			eventName = "ready-count-playing"
 		case models.StatusVoting:
 			// Send vote count for voting phase
-			voteCount := len(g.Votes)
-			totalPlayers := len(lobby.Players)
-			countHTML = ctx.VoteCount(voteCount, totalPlayers)
+			voted, voters := g.VoteProgress(len(lobby.Players))
+			countHTML = ctx.VoteCount(lang, voted, voters)
 			eventName = "vote-count-voting"
 		}
 		lobby.RUnlock()
-		if debug {
-			log.Printf("handleSSE: sending initial %s to player %s", eventName, playerID)
-		}
+		logger.Debug("Sending initial game state over SSE", "event", eventName)
 		fmt.Fprintf(w, "event: %s\n%s\n", eventName, formatSSEData(countHTML))
 	} else {
 		// No game - send lobby data
-		playerListHTML := ctx.PlayerList(lobby.Players, lobby.Scores, lobby.Host)
+		playerListHTML := ctx.PlayerList(lang, lobby)
 		hostControlsHTML := ctx.HostControls(lobby, playerID)
 		lobby.RUnlock()
-		if debug {
-			log.Printf("handleSSE: sending initial lobby data to player %s", playerID)
-		}
+		logger.Debug("Sending initial lobby state over SSE")
 		fmt.Fprintf(w, "event: %s\n%s\n", sse.EventPlayerUpdate, formatSSEData(playerListHTML))
 		fmt.Fprintf(w, "event: %s\n%s\n", sse.EventControlsUpdate, formatSSEData(hostControlsHTML))
 	}
@@ -179,14 +141,24 @@ func (ctx *Context) HandleSSE(w http.ResponseWriter, r *http.Request) {
 	for {
 		select {
 		case <-reqCtx.Done():
-			log.Printf("handleSSE: SSE connection closed for player %s in room %s (normal navigation or disconnect)", playerID, roomCode)
-			// Don't call handlePlayerDisconnect here - SSE connections close during normal page navigation
+			logger.Info("SSE connection closed (navigation or disconnect)")
+			// A closed SSE connection doesn't mean the player left - connections close during normal page navigation
 			// Players are only removed when they explicitly leave via HandleLeaveLobby or HandleLeaveLobbyWithHost
 			return
-		case msg := <-clientChan:
-			if debug {
-				log.Printf("handleSSE: sending event=%s to player %s", msg.Event, playerID)
+		case <-ctx.ShutdownDone():
+			// Server is shutting down: deliver anything still queued (e.g. the restart notice) and release the connection
+			for {
+				select {
+				case msg := <-clientChan:
+					fmt.Fprintf(w, "event: %s\n%s\n", msg.Event, formatSSEData(msg.Data))
+				default:
+					w.(http.Flusher).Flush()
+					logger.Info("Closing SSE connection for server shutdown")
+					return
+				}
 			}
+		case msg := <-clientChan:
+			logger.Debug("Sending SSE event", "event", msg.Event)
 			fmt.Fprintf(w, "event: %s\n%s\n", msg.Event, formatSSEData(msg.Data))
 			w.(http.Flusher).Flush()
 		}
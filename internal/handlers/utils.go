@@ -1,31 +1,28 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/secure"
 )
 
-// getLobbyAndPlayer validates membership using session cookie
-func (ctx *Context) getLobbyAndPlayer(r *http.Request, roomCode string) (*models.Lobby, string, error) {
-	lobby, exists := ctx.LobbyStore.Get(roomCode)
-	if !exists {
-		return nil, "", fmt.Errorf("lobby not found")
-	}
-	cookie, err := r.Cookie("player_id")
-	if err != nil {
-		return nil, "", fmt.Errorf("no session")
-	}
-	playerID := cookie.Value
-	lobby.RLock()
-	_, member := lobby.Players[playerID]
-	lobby.RUnlock()
-	if !member {
-		return nil, "", fmt.Errorf("not a member")
-	}
-	return lobby, playerID, nil
+// isSecureRequest reports whether the client connection is HTTPS (directly or via a trusted proxy)
+func (ctx *Context) isSecureRequest(r *http.Request) bool {
+	return secure.IsHTTPS(r, ctx.Config.TLS.TrustProxy)
+}
+
+// setSessionCookie stores the player's session, marked Secure whenever the request came in over HTTPS
+func (ctx *Context) setSessionCookie(w http.ResponseWriter, r *http.Request, playerID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    playerID,
+		Path:     ctx.Config.Path("/"),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   ctx.isSecureRequest(r),
+	})
 }
 
 // isNameTaken checks if a name is already taken in the lobby (case-insensitive)
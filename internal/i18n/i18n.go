@@ -0,0 +1,114 @@
+// Package i18n loads the UI message catalogs and picks a language for each request.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is used when nothing else matches, and for keys missing from another catalog
+const DefaultLanguage = "en"
+
+// Language describes a supported language for pickers
+type Language struct {
+	Code string
+	Name string // the language's own name, e.g. "Deutsch"
+}
+
+// Catalog holds the messages of every supported language
+type Catalog struct {
+	messages  map[string]map[string]string // language -> key -> message
+	languages []string                     // DefaultLanguage first, then alphabetical
+	matcher   language.Matcher
+}
+
+// Load reads one <lang>.json file per language from fsys, each a flat
+// object of message keys to fmt-style format strings. The default language must be present.
+func Load(fsys fs.FS) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]string)}
+	for _, file := range files {
+		lang := strings.TrimSuffix(path.Base(file), ".json")
+		if _, err := language.Parse(lang); err != nil {
+			return nil, fmt.Errorf("catalog %s: invalid language tag: %w", file, err)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		c.messages[lang] = messages
+		if lang != DefaultLanguage {
+			c.languages = append(c.languages, lang)
+		}
+	}
+	if _, ok := c.messages[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("missing catalog for default language %q", DefaultLanguage)
+	}
+	slices.Sort(c.languages)
+	c.languages = append([]string{DefaultLanguage}, c.languages...)
+
+	// The first tag is the matcher's fallback
+	tags := make([]language.Tag, len(c.languages))
+	for i, lang := range c.languages {
+		tags[i] = language.MustParse(lang)
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+// Languages lists the supported languages, default first
+func (c *Catalog) Languages() []Language {
+	langs := make([]Language, len(c.languages))
+	for i, code := range c.languages {
+		langs[i] = Language{Code: code, Name: c.T(code, "language.name")}
+	}
+	return langs
+}
+
+// Supports reports whether lang has a catalog
+func (c *Catalog) Supports(lang string) bool {
+	_, ok := c.messages[lang]
+	return ok
+}
+
+// Lookup returns the message for key in lang, falling back to the default language
+func (c *Catalog) Lookup(lang, key string) (string, bool) {
+	if msg, ok := c.messages[lang][key]; ok {
+		return msg, true
+	}
+	msg, ok := c.messages[DefaultLanguage][key]
+	return msg, ok
+}
+
+// T translates key into lang, formatting args into the message.
+// Unknown keys come back as the key itself so they stand out on the page.
+func (c *Catalog) T(lang, key string, args ...any) string {
+	msg, ok := c.Lookup(lang, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Negotiate picks the best supported language for an Accept-Language header
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	_, index := language.MatchStrings(c.matcher, acceptLanguage)
+	return c.languages[index]
+}
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sus"
+
+var (
+	// SSEConnections tracks currently open SSE streams across all lobbies
+	SSEConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sse_connections",
+		Help:      "Number of open Server-Sent Events connections.",
+	})
+
+	// SSEBroadcastDuration measures how long fanning out one event to a lobby takes
+	SSEBroadcastDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sse_broadcast_duration_seconds",
+		Help:      "Time taken to deliver one broadcast to all targeted SSE clients.",
+		Buckets:   []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 2.5},
+	}, []string{"kind"})
+
+	// SSESendTimeouts counts messages dropped because a client's channel stayed full
+	SSESendTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sse_send_timeouts_total",
+		Help:      "Messages dropped because an SSE client did not accept them in time.",
+	}, []string{"kind"})
+
+	gamesStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_started_total",
+		Help:      "Games started, by game mode.",
+	}, []string{"mode"})
+
+	gamesFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_finished_total",
+		Help:      "Games finished, by game mode and outcome (spy, innocents, aborted).",
+	}, []string{"mode", "outcome"})
+
+	phaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "game_phase_duration_seconds",
+		Help:      "Time spent in each game phase.",
+		Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 900, 1800},
+	}, []string{"phase"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	// Decided games for the spy win rate gauge (aborted games are excluded)
+	spyWins      atomic.Int64
+	decidedGames atomic.Int64
+)
+
+// Outcome labels for GameFinished
+const (
+	OutcomeSpy       = "spy"
+	OutcomeInnocents = "innocents"
+	OutcomeAborted   = "aborted"
+)
+
+// SSE broadcast kinds used as label values
+const (
+	KindBroadcast    = "broadcast"
+	KindPersonalized = "personalized"
+	KindPlayer       = "player"
+)
+
+func init() {
+	prometheus.MustRegister(
+		SSEConnections,
+		SSEBroadcastDuration,
+		SSESendTimeouts,
+		gamesStarted,
+		gamesFinished,
+		phaseDuration,
+		httpDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "spy_win_rate",
+			Help:      "Fraction of decided games won by the spy since the server started.",
+		}, spyWinRate),
+	)
+}
+
+// StoreStats is the subset of the lobby store needed for lobby and player gauges
+type StoreStats interface {
+	Count() int
+	PlayerCount() int
+}
+
+// RegisterStore exposes active lobby and player counts read from s at scrape time
+func RegisterStore(s StoreStats) {
+	prometheus.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "lobbies_active",
+			Help:      "Number of open lobbies.",
+		}, func() float64 { return float64(s.Count()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "players_active",
+			Help:      "Number of players across all lobbies.",
+		}, func() float64 { return float64(s.PlayerCount()) }),
+	)
+}
+
+// Handler serves the Prometheus scrape endpoint
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InstrumentRoute records request latency for h under the given route label
+func InstrumentRoute(route string, h http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerDuration(
+		httpDuration.MustCurryWith(prometheus.Labels{"route": route}), h)
+}
+
+// GameStarted records a new game in the given mode
+func GameStarted(mode string) {
+	gamesStarted.WithLabelValues(mode).Inc()
+}
+
+// GameFinished records the outcome of a game
+func GameFinished(mode, outcome string) {
+	gamesFinished.WithLabelValues(mode, outcome).Inc()
+	switch outcome {
+	case OutcomeSpy:
+		spyWins.Add(1)
+		decidedGames.Add(1)
+	case OutcomeInnocents:
+		decidedGames.Add(1)
+	}
+}
+
+// PhaseCompleted records how long a game spent in phase
+func PhaseCompleted(phase string, d time.Duration) {
+	phaseDuration.WithLabelValues(phase).Observe(d.Seconds())
+}
+
+// ObserveBroadcast records the duration of a broadcast that started at start
+func ObserveBroadcast(kind string, start time.Time) {
+	SSEBroadcastDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+}
+
+func spyWinRate() float64 {
+	decided := decidedGames.Load()
+	if decided == 0 {
+		return 0
+	}
+	return float64(spyWins.Load()) / float64(decided)
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Account is an optional identity that links a person's players across lobbies.
+// There is no password: whoever holds the sign-in token is the account holder.
+type Account struct {
+	ID        string
+	Nickname  string
+	TokenHash string // SHA-256 of the sign-in token, hex encoded
+	CreatedAt time.Time
+	Stats     AccountStats
+}
+
+// AccountStats accumulates an account's results over every finished game
+type AccountStats struct {
+	GamesPlayed  int
+	GamesWon     int
+	SpyGames     int
+	SpyWins      int
+	VotesCast    int
+	CorrectVotes int            // votes for a spy
+	Locations    map[string]int // location word -> games played there
+}
+
+// GameResult is what one player's stats gain from a finished game
+type GameResult struct {
+	Won            bool
+	WasSpy         bool
+	Voted          bool
+	VotedCorrectly bool
+	Location       string // empty when no location was chosen
+}
+
+// LocationCount is a location and how often it came up
+type LocationCount struct {
+	Word  string
+	Count int
+}
+
+// Add folds one game's result into the stats
+func (s *AccountStats) Add(r GameResult) {
+	s.GamesPlayed++
+	if r.Won {
+		s.GamesWon++
+	}
+	if r.WasSpy {
+		s.SpyGames++
+		if r.Won {
+			s.SpyWins++
+		}
+	}
+	if r.Voted {
+		s.VotesCast++
+		if r.VotedCorrectly {
+			s.CorrectVotes++
+		}
+	}
+	if r.Location != "" {
+		if s.Locations == nil {
+			s.Locations = make(map[string]int)
+		}
+		s.Locations[r.Location]++
+	}
+}
+
+// WinRate is the percentage of games won
+func (s AccountStats) WinRate() int {
+	return percent(s.GamesWon, s.GamesPlayed)
+}
+
+// SpyWinRate is the percentage of games won as a spy
+func (s AccountStats) SpyWinRate() int {
+	return percent(s.SpyWins, s.SpyGames)
+}
+
+// DetectionAccuracy is the percentage of votes that named a spy
+func (s AccountStats) DetectionAccuracy() int {
+	return percent(s.CorrectVotes, s.VotesCast)
+}
+
+// FavoriteLocations returns the n most played locations, most played first
+func (s AccountStats) FavoriteLocations(n int) []LocationCount {
+	locations := make([]LocationCount, 0, len(s.Locations))
+	for word, count := range s.Locations {
+		locations = append(locations, LocationCount{Word: word, Count: count})
+	}
+	slices.SortFunc(locations, func(a, b LocationCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Word, b.Word))
+	})
+	return locations[:min(n, len(locations))]
+}
+
+func percent(part, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return part * 100 / total
+}
@@ -0,0 +1,256 @@
+package models
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxArchivedGames is how many finished games a lobby keeps in its archive
+const MaxArchivedGames = 50
+
+// GameRecord is an archived finished game. Players are referred to by name, as
+// they were when the game was set up.
+type GameRecord struct {
+	Number       int               `json:"number"` // the game's number in the lobby, starting at 1
+	Mode         GameMode          `json:"mode"`
+	Voting       VotingSystem      `json:"voting"`
+	Seed         int64             `json:"seed"`
+	Location     string            `json:"location"`
+	Players      []string          `json:"players"`
+	Spies        []string          `json:"spies"`
+	VotedOut     string            `json:"voted_out,omitempty"` // from the game's final tally
+	InnocentWon  bool              `json:"innocent_won"`
+	SpyForfeited bool              `json:"spy_forfeited"`
+	SpyGuess     string            `json:"spy_guess,omitempty"` // the location a spy guessed to end play
+	Challenges   []ChallengeRecord `json:"challenges,omitempty"`
+	VoteRounds   [][]BallotRecord  `json:"vote_rounds"`
+	Trials       []TrialRecord     `json:"trials,omitempty"` // live accusations and voting rounds' trials, in order
+	Points       map[string]int    `json:"points,omitempty"`
+	Phases       []PhaseRecord     `json:"phases"`
+	Departures   []DepartureRecord `json:"departures,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   time.Time         `json:"finished_at"`
+}
+
+// ChallengeRecord is one player's challenge and what the others decided about it
+type ChallengeRecord struct {
+	Player    string           `json:"player"`
+	Challenge string           `json:"challenge"`
+	Verdict   ChallengeVerdict `json:"verdict"`
+}
+
+// BallotRecord is one vote in one voting round. The voter is left out when the
+// game used anonymous voting.
+type BallotRecord struct {
+	Voter    string   `json:"voter,omitempty"`
+	Suspects []string `json:"suspects"` // in order of preference where the voting system has one
+}
+
+// TrialRecord is one player standing trial: accused during play, or by a
+// voting round under accusation voting
+type TrialRecord struct {
+	Accuser  string          `json:"accuser,omitempty"` // set for a live accusation
+	Accused  string          `json:"accused"`
+	Verdicts []VerdictRecord `json:"verdicts"`
+}
+
+// VerdictRecord is one player's verdict in a trial. The voter is left out when
+// the game used anonymous voting.
+type VerdictRecord struct {
+	Voter  string `json:"voter,omitempty"`
+	Guilty bool   `json:"guilty"`
+}
+
+// PhaseRecord is how long the game spent in one phase
+type PhaseRecord struct {
+	Phase     GameStatus `json:"phase"`
+	StartedAt time.Time  `json:"started_at"`
+	Seconds   int        `json:"seconds"`
+}
+
+// DepartureRecord is a player who left while the game was on
+type DepartureRecord struct {
+	Player string     `json:"player"`
+	Phase  GameStatus `json:"phase"`
+	At     time.Time  `json:"at"`
+}
+
+// PhaseTiming is when the game entered a phase
+type PhaseTiming struct {
+	Status    GameStatus
+	StartedAt time.Time
+}
+
+// Departure is a player who left the current game, kept with the challenge
+// they had since their game state is deleted
+type Departure struct {
+	PlayerID  string
+	Challenge string
+	Phase     GameStatus
+	At        time.Time
+}
+
+// EndCurrentGame clears the current game, archiving it first if it finished (must be called with lock held)
+func (l *Lobby) EndCurrentGame() {
+	if g := l.CurrentGame; g != nil && g.Status == StatusFinished {
+		l.Archive = append(l.Archive, l.record(g))
+		if len(l.Archive) > MaxArchivedGames {
+			l.Archive = slices.Delete(l.Archive, 0, len(l.Archive)-MaxArchivedGames)
+		}
+	}
+	l.CurrentGame = nil
+}
+
+// roster returns the names of everyone dealt into g: those its setup drew from,
+// or for games restored from before setups were recorded, the players the log
+// says joined, named as in the lobby if the log doesn't and by ID if neither
+// does (must be called with lock held)
+func (l *Lobby) roster(g *Game) map[string]string {
+	if g.Setup != nil {
+		return g.Setup.Players
+	}
+	names := make(map[string]string)
+	for _, e := range g.Log {
+		if e.Type != EventPlayerJoined {
+			continue
+		}
+		switch p, ok := l.Players[e.Player]; {
+		case e.Name != "":
+			names[e.Player] = e.Name
+		case ok:
+			names[e.Player] = p.Name
+		default:
+			names[e.Player] = e.Player
+		}
+	}
+	return names
+}
+
+// record summarizes a finished game for the archive (must be called with lock held)
+func (l *Lobby) record(g *Game) GameRecord {
+	names := l.roster(g)
+	name := func(id string) string {
+		if n, ok := names[id]; ok {
+			return n
+		}
+		return id
+	}
+
+	r := GameRecord{
+		Number:       l.History.Games,
+		Mode:         g.Mode,
+		Voting:       g.Settings.VotingSystem,
+		Seed:         g.Seed,
+		Players:      slices.Sorted(maps.Values(names)),
+		InnocentWon:  g.InnocentWon,
+		SpyForfeited: g.SpyForfeited,
+		Points:       make(map[string]int),
+	}
+	if g.Location != nil {
+		r.Location = g.Location.Word
+	}
+	if g.SpyGuess != nil {
+		r.SpyGuess = g.SpyGuess.Word
+	}
+	if g.Tally != nil && g.Tally.MostVoted != "" {
+		r.VotedOut = name(g.Tally.MostVoted)
+	}
+	for _, n := range g.Spies {
+		r.Spies = append(r.Spies, n)
+	}
+	slices.Sort(r.Spies)
+
+	present := l.PlayerIDs()
+	for _, id := range present {
+		if info, ok := g.PlayerInfo[id]; ok && info.Challenge != "" {
+			r.Challenges = append(r.Challenges, ChallengeRecord{
+				Player:    name(id),
+				Challenge: info.Challenge,
+				Verdict:   g.ChallengeVerdict(id, present),
+			})
+		}
+	}
+	for _, d := range g.Departures {
+		r.Departures = append(r.Departures, DepartureRecord{Player: name(d.PlayerID), Phase: d.Phase, At: d.At})
+		if d.Challenge != "" {
+			// Reviews given before they left still count
+			r.Challenges = append(r.Challenges, ChallengeRecord{Player: name(d.PlayerID), Challenge: d.Challenge, Verdict: g.ChallengeVerdict(d.PlayerID, present)})
+		}
+	}
+
+	for _, votes := range append(slices.Clone(g.PastVotes), g.Votes) {
+		if len(votes) == 0 {
+			continue
+		}
+		round := make([]BallotRecord, 0, len(votes))
+		for voter, ballot := range votes {
+			var record BallotRecord
+			if !g.Settings.AnonymousVoting {
+				record.Voter = name(voter)
+			}
+			for _, suspect := range ballot {
+				record.Suspects = append(record.Suspects, name(suspect))
+			}
+			round = append(round, record)
+		}
+		// Anonymous ballots are sorted by what they say, so the order gives nobody away
+		slices.SortFunc(round, func(a, b BallotRecord) int {
+			if c := strings.Compare(a.Voter, b.Voter); c != 0 {
+				return c
+			}
+			return slices.Compare(a.Suspects, b.Suspects)
+		})
+		r.VoteRounds = append(r.VoteRounds, round)
+	}
+
+	for _, t := range g.Trials() {
+		trial := TrialRecord{Accused: name(t.Accused), Verdicts: make([]VerdictRecord, 0, len(t.Verdicts))}
+		if t.Live() {
+			// Stopping play is done in the open, so the accuser is named either way
+			trial.Accuser = name(t.Accuser)
+		}
+		for voter, guilty := range t.Verdicts {
+			verdict := VerdictRecord{Guilty: guilty}
+			if !g.Settings.AnonymousVoting {
+				verdict.Voter = name(voter)
+			}
+			trial.Verdicts = append(trial.Verdicts, verdict)
+		}
+		// Sorted like ballots, so anonymous verdicts give nobody away
+		slices.SortFunc(trial.Verdicts, func(a, b VerdictRecord) int {
+			if c := strings.Compare(a.Voter, b.Voter); c != 0 {
+				return c
+			}
+			switch {
+			case a.Guilty == b.Guilty:
+				return 0
+			case a.Guilty:
+				return -1
+			}
+			return 1
+		})
+		r.Trials = append(r.Trials, trial)
+	}
+
+	for id, awards := range g.Awards {
+		r.Points[name(id)] = TotalPoints(awards)
+	}
+
+	for i, p := range g.Phases {
+		if p.Status == StatusFinished {
+			r.FinishedAt = p.StartedAt
+			break
+		}
+		end := time.Now()
+		if i+1 < len(g.Phases) {
+			end = g.Phases[i+1].StartedAt
+		}
+		r.Phases = append(r.Phases, PhaseRecord{Phase: p.Status, StartedAt: p.StartedAt, Seconds: int(end.Sub(p.StartedAt).Seconds())})
+	}
+	if len(g.Phases) > 0 {
+		r.StartedAt = g.Phases[0].StartedAt
+	}
+	return r
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordKeepsTrials(t *testing.T) {
+	g := playedGame()
+	lobby := &Lobby{}
+
+	want := []TrialRecord{
+		{Accuser: "Ann", Accused: "Ben", Verdicts: []VerdictRecord{{Voter: "Ann", Guilty: true}, {Voter: "Cat"}}},
+		{Accused: "Eve", Verdicts: []VerdictRecord{{Voter: "Ann", Guilty: true}}},
+	}
+	if got := lobby.record(g).Trials; !reflect.DeepEqual(got, want) {
+		t.Errorf("trials = %+v, want %+v", got, want)
+	}
+
+	g.Settings.AnonymousVoting = true
+	want = []TrialRecord{
+		{Accuser: "Ann", Accused: "Ben", Verdicts: []VerdictRecord{{Guilty: true}, {}}},
+		{Accused: "Eve", Verdicts: []VerdictRecord{{Guilty: true}}},
+	}
+	if got := lobby.record(g).Trials; !reflect.DeepEqual(got, want) {
+		t.Errorf("anonymous trials = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordKeepsDepartedPlayersReviews(t *testing.T) {
+	g := playedGame()
+	// cat leaves after ann and ben confirmed her challenge
+	g.Record(GameEvent{Type: EventChallengeReviewed, Player: "ann", Target: "cat", Completed: true})
+	g.Record(GameEvent{Type: EventChallengeReviewed, Player: "ben", Target: "cat", Completed: true})
+	g.Record(GameEvent{Type: EventPlayerLeft, Player: "cat"})
+	lobby := &Lobby{Players: map[string]*Player{"ann": {ID: "ann", Name: "Ann"}, "ben": {ID: "ben", Name: "Ben"}, "eve": {ID: "eve", Name: "Eve"}}}
+
+	verdicts := make(map[string]ChallengeVerdict)
+	for _, c := range lobby.record(g).Challenges {
+		verdicts[c.Player] = c.Verdict
+	}
+	want := map[string]ChallengeVerdict{"Ann": VerdictPending, "Ben": VerdictPending, "Eve": VerdictPending, "Dan": VerdictPending, "Cat": VerdictConfirmed}
+	if !reflect.DeepEqual(verdicts, want) {
+		t.Errorf("challenge verdicts = %v, want %v", verdicts, want)
+	}
+}
+
+func TestEndCurrentGameArchivesGameWithoutSetup(t *testing.T) {
+	// Restored from before setups were recorded; dan has since left the lobby
+	old := &Game{
+		Mode:     GameModeStandard,
+		Location: &Location{Word: "zoo"},
+		Spies:    map[string]string{"ben": "Ben"},
+		PlayerInfo: map[string]*GamePlayerInfo{
+			"ann": {Challenge: "hum"},
+			"ben": {Challenge: "wink", IsSpy: true},
+			"dan": {Challenge: "shrug"},
+		},
+		Status:      StatusFinished,
+		InnocentWon: true,
+	}
+	log := LogFromState(old, map[string]string{"ann": "Ann", "ben": ""}, 3)
+	g, err := FoldGame(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lobby := &Lobby{
+		Players:     map[string]*Player{"ann": {ID: "ann", Name: "Ann"}, "ben": {ID: "ben", Name: "Benny"}},
+		CurrentGame: g,
+	}
+
+	lobby.EndCurrentGame()
+	if lobby.CurrentGame != nil || len(lobby.Archive) != 1 {
+		t.Fatalf("game %v ended with archive %+v", lobby.CurrentGame, lobby.Archive)
+	}
+	r := lobby.Archive[0]
+	if want := []string{"Ann", "Benny", "dan"}; !reflect.DeepEqual(r.Players, want) {
+		t.Errorf("players = %v, want %v", r.Players, want)
+	}
+	if r.Location != "zoo" || !r.InnocentWon || !reflect.DeepEqual(r.Spies, []string{"Ben"}) {
+		t.Errorf("record = %+v", r)
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+// ChallengeVerdict is what the other players decided about one player's challenge
+type ChallengeVerdict string
+
+const (
+	VerdictPending   ChallengeVerdict = "pending"   // no majority either way yet
+	VerdictConfirmed ChallengeVerdict = "confirmed" // a majority of reviewers say it was done
+	VerdictDisputed  ChallengeVerdict = "disputed"  // a majority can no longer confirm it
+)
+
+// ReviewChallenge records reviewerID's opinion on whether ownerID completed their challenge
+func (g *Game) ReviewChallenge(ownerID, reviewerID string, completed bool) {
+	if g.ChallengeReviews == nil {
+		g.ChallengeReviews = make(map[string]map[string]bool)
+	}
+	if g.ChallengeReviews[ownerID] == nil {
+		g.ChallengeReviews[ownerID] = make(map[string]bool)
+	}
+	g.ChallengeReviews[ownerID][reviewerID] = completed
+}
+
+// ChallengeTally counts the confirmations and disputes ownerID's challenge received
+// from the given reviewers (reviews by players who left are ignored)
+func (g *Game) ChallengeTally(ownerID string, reviewers []string) (confirms, disputes int) {
+	for _, id := range reviewers {
+		if id == ownerID {
+			continue
+		}
+		completed, ok := g.ChallengeReviews[ownerID][id]
+		switch {
+		case !ok:
+		case completed:
+			confirms++
+		default:
+			disputes++
+		}
+	}
+	return confirms, disputes
+}
+
+// ChallengeVerdict decides ownerID's challenge once a strict majority of the
+// other players in reviewers agree it was done, or enough dispute it that they can't
+func (g *Game) ChallengeVerdict(ownerID string, reviewers []string) ChallengeVerdict {
+	others := 0
+	for _, id := range reviewers {
+		if id != ownerID {
+			others++
+		}
+	}
+	confirms, disputes := g.ChallengeTally(ownerID, reviewers)
+	switch {
+	case others == 0:
+		return VerdictPending
+	case confirms > others/2:
+		return VerdictConfirmed
+	case disputes >= others-others/2:
+		return VerdictDisputed
+	default:
+		return VerdictPending
+	}
+}
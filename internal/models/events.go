@@ -0,0 +1,481 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// EventType names one kind of change to a game
+type EventType string
+
+const (
+	EventGameCreated       EventType = "game_created"       // Settings, Seed
+	EventPlayerJoined      EventType = "player_joined"      // Player, Name: someone in the lobby when the game started
+	EventPhaseChanged      EventType = "phase_changed"      // Status
+	EventWordSubmitted     EventType = "word_submitted"     // Player, Word
+	EventSetupDrawn        EventType = "setup_drawn"        // Setup
+	EventReadyToggled      EventType = "ready_toggled"      // Player, Ready: for the current phase
+	EventVoteCast          EventType = "vote_cast"          // Player, Ballot
+	EventTrialStarted      EventType = "trial_started"      // Target: accused by the round's votes
+	EventVerdictCast       EventType = "verdict_cast"       // Player, Guilty
+	EventAccusationMade    EventType = "accusation_made"    // Player stopped play to accuse Target
+	EventPlayResumed       EventType = "play_resumed"       // the accusation failed; the clock runs again
+	EventLocationGuessed   EventType = "location_guessed"   // Player, a spy, guessed Word as the location
+	EventRevoteStarted     EventType = "revote_started"     // the undecided round is put aside
+	EventPlayerLeft        EventType = "player_left"        // Player
+	EventGameFinished      EventType = "game_finished"      // InnocentWon, Tally
+	EventPointsAwarded     EventType = "points_awarded"     // Awards, replacing earlier ones
+	EventChallengeReviewed EventType = "challenge_reviewed" // Player reviewed Target's challenge, Completed
+)
+
+// GameEvent is one entry in a game's log. Only the fields its type lists are set.
+type GameEvent struct {
+	Seq         int                     `json:"seq"`
+	At          time.Time               `json:"at"`
+	Type        EventType               `json:"type"`
+	Player      string                  `json:"player,omitempty"`
+	Name        string                  `json:"name,omitempty"`
+	Target      string                  `json:"target,omitempty"`
+	Word        string                  `json:"word,omitempty"`
+	Status      GameStatus              `json:"status,omitempty"`
+	Ready       bool                    `json:"ready,omitempty"`
+	Completed   bool                    `json:"completed,omitempty"`
+	Guilty      bool                    `json:"guilty,omitempty"`
+	Ballot      Ballot                  `json:"ballot,omitempty"`
+	InnocentWon bool                    `json:"innocent_won,omitempty"`
+	Tally       *VoteResult             `json:"tally,omitempty"`
+	Settings    *LobbySettings          `json:"settings,omitempty"`
+	Seed        int64                   `json:"seed,omitempty"`
+	Setup       *GameSetup              `json:"setup,omitempty"`
+	Awards      map[string][]PointAward `json:"awards,omitempty"`
+}
+
+// NewGame starts a game with the given settings, its log opening with game_created
+func NewGame(settings LobbySettings, seed int64) *Game {
+	g := &Game{}
+	g.Record(GameEvent{Type: EventGameCreated, Settings: &settings, Seed: seed})
+	return g
+}
+
+// FoldGame rebuilds a game by applying its log from the start. A log has to
+// open with game_created, which makes the maps the other events write to.
+func FoldGame(log []GameEvent) (*Game, error) {
+	if len(log) > 0 && log[0].Type != EventGameCreated {
+		return nil, fmt.Errorf("log opens with %s instead of %s", log[0].Type, EventGameCreated)
+	}
+	g := &Game{}
+	for _, e := range log {
+		g.Apply(e)
+	}
+	g.Log = slices.Clone(log)
+	return g, nil
+}
+
+// Record stamps e, appends it to the log and applies it. Every change to a
+// game goes through here so the log alone can rebuild it.
+func (g *Game) Record(e GameEvent) {
+	e.Seq = len(g.Log) + 1
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	g.Log = append(g.Log, e)
+	g.Apply(e)
+}
+
+// Apply changes the game's state as e describes, without logging it
+func (g *Game) Apply(e GameEvent) {
+	switch e.Type {
+	case EventGameCreated:
+		g.Mode = e.Settings.Mode
+		g.Settings = *e.Settings
+		g.Seed = e.Seed
+		g.PlayerInfo = make(map[string]*GamePlayerInfo)
+		g.ReadyToReveal = make(map[string]bool)
+		g.ReadyAfterReveal = make(map[string]bool)
+		g.ReadyToVote = make(map[string]bool)
+		g.Votes = make(map[string]Ballot)
+		g.Accusations = make(map[string]int)
+		g.VoteRound = 1
+		if g.Mode == GameModeCustomWords {
+			g.CustomWords = make(map[string]string)
+			g.WordsSubmitted = make(map[string]bool)
+		}
+
+	case EventPlayerJoined:
+		// The roster lives in the log; nothing to track until the setup
+
+	case EventPhaseChanged:
+		g.Status = e.Status
+		g.PhaseStartedAt = e.At
+		g.Phases = append(g.Phases, PhaseTiming{Status: e.Status, StartedAt: e.At})
+		if e.Status == StatusPlaying {
+			g.PlayStartedAt = e.At
+			g.FirstQuestioner = g.firstQuestioner()
+			// Every round of play comes with a fresh set of accusations
+			g.Accusations = make(map[string]int)
+		}
+
+	case EventWordSubmitted:
+		g.CustomWords[e.Player] = e.Word
+		g.WordsSubmitted[e.Player] = true
+
+	case EventSetupDrawn:
+		s := e.Setup
+		g.Setup = s.Input
+		g.Location = s.Location
+		g.SelectedCustomWord = s.CustomWord
+		g.Spies = maps.Clone(s.Spies)
+		g.QuestionOrder = slices.Clone(s.QuestionOrder)
+		for id, challenge := range s.Challenges {
+			g.PlayerInfo[id] = &GamePlayerInfo{Challenge: challenge, IsSpy: g.IsSpy(id)}
+		}
+
+	case EventReadyToggled:
+		if ready := g.ReadyMap(); ready != nil {
+			ready[e.Player] = e.Ready
+		}
+
+	case EventVoteCast:
+		if ballot, voted := g.Votes[e.Player]; !voted || !slices.Equal(ballot, e.Ballot) {
+			// A changed ballot counts from when it was cast
+			g.VoteOrder = append(slices.DeleteFunc(g.VoteOrder, func(id string) bool { return id == e.Player }), e.Player)
+		}
+		g.Votes[e.Player] = e.Ballot
+
+	case EventTrialStarted:
+		g.Trial = &Trial{Accused: e.Target, Verdicts: make(map[string]bool)}
+
+	case EventVerdictCast:
+		g.Trial.Verdicts[e.Player] = e.Guilty
+
+	case EventAccusationMade:
+		// The accuser's verdict goes without saying
+		g.Trial = &Trial{Accused: e.Target, Accuser: e.Player, Verdicts: map[string]bool{e.Player: true}}
+		if g.Accusations == nil {
+			// Games restored from before accusations have none
+			g.Accusations = make(map[string]int)
+		}
+		g.Accusations[e.Player]++
+		g.PausedAt = e.At
+
+	case EventPlayResumed:
+		g.resumePlay(e.At)
+
+	case EventLocationGuessed:
+		g.SpyGuess = &LocationGuess{Spy: e.Player, Word: e.Word, Correct: g.Location != nil && e.Word == g.Location.Word}
+
+	case EventRevoteStarted:
+		g.putVotesAside()
+		g.VoteRound++
+
+	case EventPlayerLeft:
+		d := Departure{PlayerID: e.Player, Phase: g.Status, At: e.At}
+		if info, ok := g.PlayerInfo[e.Player]; ok {
+			d.Challenge = info.Challenge
+		}
+		g.Departures = append(g.Departures, d)
+		if g.Status == StatusFinished {
+			// The results stay as the game ended
+			break
+		}
+		if g.IsSpy(e.Player) {
+			g.SpyForfeited = true
+		}
+		delete(g.PlayerInfo, e.Player)
+		delete(g.ReadyToReveal, e.Player)
+		delete(g.ReadyAfterReveal, e.Player)
+		delete(g.ReadyToVote, e.Player)
+		delete(g.Votes, e.Player)
+		if t := g.Trial; t != nil && t.Live() {
+			// An accusation is called off when anyone leaves
+			g.resumePlay(e.At)
+		} else if t != nil {
+			delete(t.Verdicts, e.Player)
+			if t.Accused == e.Player {
+				// Nobody left to judge; the round's votes start over
+				g.putVotesAside()
+			}
+		}
+		if g.FirstQuestioner == e.Player {
+			g.FirstQuestioner = ""
+		}
+
+	case EventGameFinished:
+		g.InnocentWon = e.InnocentWon
+		g.Tally = e.Tally
+		if g.Tally == nil {
+			// Logged before games kept their tally
+			g.Tally = &VoteResult{}
+		}
+
+	case EventPointsAwarded:
+		g.Awards = e.Awards
+
+	case EventChallengeReviewed:
+		g.ReviewChallenge(e.Target, e.Player, e.Completed)
+	}
+}
+
+// putVotesAside files the current round's ballots and starts an empty one
+func (g *Game) putVotesAside() {
+	g.PastVotes = append(g.PastVotes, g.Votes)
+	g.Votes = make(map[string]Ballot)
+	g.VoteOrder = nil
+	g.Trial = nil
+}
+
+// resumePlay ends a live accusation and restarts the clock, moving the play
+// start forward by the time it was stopped
+func (g *Game) resumePlay(at time.Time) {
+	g.PlayStartedAt = g.PlayStartedAt.Add(at.Sub(g.PausedAt))
+	g.PausedAt = time.Time{}
+	g.Trial = nil
+}
+
+// Trials replays the log to find every trial the game held, in order: live
+// accusations and voting rounds' trials, with the verdicts they ended with
+func (g *Game) Trials() []*Trial {
+	if len(g.Log) == 0 {
+		if g.Trial != nil {
+			return []*Trial{g.Trial}
+		}
+		return nil
+	}
+	var trials []*Trial
+	r := &Game{}
+	for _, e := range g.Log {
+		before := r.Trial
+		r.Apply(e)
+		if before != nil && r.Trial != before {
+			trials = append(trials, before)
+		}
+	}
+	if r.Trial != nil {
+		trials = append(trials, r.Trial)
+	}
+	return trials
+}
+
+// ReadyMap returns the readiness map of the current phase, or nil if the phase has none
+func (g *Game) ReadyMap() map[string]bool {
+	switch g.Status {
+	case StatusReadyCheck:
+		return g.ReadyToReveal
+	case StatusRoleReveal:
+		return g.ReadyAfterReveal
+	case StatusPlaying:
+		return g.ReadyToVote
+	default:
+		return nil
+	}
+}
+
+// firstQuestioner returns the first player in the question order who is still in the game
+func (g *Game) firstQuestioner() string {
+	for _, id := range g.QuestionOrder {
+		if _, ok := g.PlayerInfo[id]; ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// LogFromState writes a log for a game kept from before games had one, so
+// later changes are recorded onto a game_created event. Folding it gives back
+// the game as far as its state tells: accusations that were called off come
+// back without their target, and every earlier voting round counts as a
+// revote. names are the players' names by ID; settings the game is missing
+// are filled in as Normalized does with defaultVoteRounds.
+func LogFromState(g *Game, names map[string]string, defaultVoteRounds int) []GameEvent {
+	phases := g.Phases
+	if len(phases) == 0 && g.Status != "" {
+		// Kept from before phase timing: the play timer runs from PlayStartedAt
+		current := PhaseTiming{Status: g.Status, StartedAt: g.PhaseStartedAt}
+		switch {
+		case g.PlayStartedAt.IsZero():
+			phases = []PhaseTiming{current}
+		case g.Status == StatusPlaying:
+			phases = []PhaseTiming{{Status: StatusPlaying, StartedAt: g.PlayStartedAt}}
+		case g.Status == StatusVoting || g.Status == StatusFinished:
+			phases = []PhaseTiming{{Status: StatusPlaying, StartedAt: g.PlayStartedAt}, current}
+		default:
+			phases = []PhaseTiming{current}
+		}
+	}
+	var start time.Time
+	if len(phases) > 0 {
+		start = phases[0].StartedAt
+	}
+
+	r := &Game{}
+	settings := g.Settings.Normalized(defaultVoteRounds)
+	if g.Mode != "" {
+		settings.Mode = g.Mode
+	}
+	r.Record(GameEvent{Type: EventGameCreated, At: start, Settings: &settings, Seed: g.Seed})
+
+	// Players who left are still dealt in so their departure keeps their challenge
+	challenges := make(map[string]string)
+	for id, info := range g.PlayerInfo {
+		challenges[id] = info.Challenge
+	}
+	for _, d := range g.Departures {
+		challenges[d.PlayerID] = d.Challenge
+	}
+	for id := range g.WordsSubmitted {
+		if _, ok := challenges[id]; !ok {
+			challenges[id] = ""
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(challenges)) {
+		r.Record(GameEvent{Type: EventPlayerJoined, At: start, Player: id, Name: names[id]})
+	}
+
+	wordsLogged := false
+	logWords := func(at time.Time) {
+		if wordsLogged || r.CustomWords == nil {
+			return
+		}
+		wordsLogged = true
+		for _, id := range slices.Sorted(maps.Keys(g.CustomWords)) {
+			r.Record(GameEvent{Type: EventWordSubmitted, At: at, Player: id, Word: g.CustomWords[id]})
+		}
+	}
+	questionOrder := g.QuestionOrder
+	if len(questionOrder) == 0 && g.FirstQuestioner != "" {
+		// Kept from before the question order: play starts with the questioner it picked
+		questionOrder = []string{g.FirstQuestioner}
+	}
+	setupLogged := g.Location == nil && g.SelectedCustomWord == "" && len(g.Spies) == 0
+	logSetup := func(at time.Time) {
+		if setupLogged {
+			return
+		}
+		setupLogged = true
+		logWords(at)
+		r.Record(GameEvent{Type: EventSetupDrawn, At: at, Setup: &GameSetup{
+			Input:         g.Setup,
+			Location:      g.Location,
+			CustomWord:    g.SelectedCustomWord,
+			Spies:         g.Spies,
+			Challenges:    challenges,
+			QuestionOrder: questionOrder,
+		}})
+	}
+	guessLogged := g.SpyGuess == nil
+	logGuess := func(at time.Time) {
+		if !guessLogged {
+			guessLogged = true
+			r.Record(GameEvent{Type: EventLocationGuessed, At: at, Player: g.SpyGuess.Spy, Word: g.SpyGuess.Word})
+		}
+	}
+	departed := make([]bool, len(g.Departures))
+	logDepartures := func(phase GameStatus, all bool) {
+		for i, d := range g.Departures {
+			if !departed[i] && (all || d.Phase == phase) {
+				departed[i] = true
+				r.Record(GameEvent{Type: EventPlayerLeft, At: d.At, Player: d.PlayerID})
+			}
+		}
+	}
+	logReady := func(ready map[string]bool, at time.Time) {
+		for _, id := range slices.Sorted(maps.Keys(ready)) {
+			if ready[id] {
+				r.Record(GameEvent{Type: EventReadyToggled, At: at, Player: id, Ready: true})
+			}
+		}
+	}
+	logBallots := func(votes map[string]Ballot, order []string, at time.Time) {
+		for _, id := range order {
+			if ballot, ok := votes[id]; ok {
+				r.Record(GameEvent{Type: EventVoteCast, At: at, Player: id, Ballot: ballot})
+			}
+		}
+		for _, id := range slices.Sorted(maps.Keys(votes)) {
+			if !slices.Contains(order, id) {
+				r.Record(GameEvent{Type: EventVoteCast, At: at, Player: id, Ballot: votes[id]})
+			}
+		}
+	}
+	logVerdicts := func(t *Trial, at time.Time) {
+		for _, id := range slices.Sorted(maps.Keys(t.Verdicts)) {
+			if id != t.Accuser {
+				r.Record(GameEvent{Type: EventVerdictCast, At: at, Player: id, Guilty: t.Verdicts[id]})
+			}
+		}
+	}
+
+	last := make(map[GameStatus]int)
+	for i, p := range phases {
+		last[p.Status] = i
+	}
+	for i, p := range phases {
+		at := p.StartedAt
+		if p.Status != StatusWaiting && p.Status != StatusWordCollection {
+			logSetup(at)
+		}
+		if p.Status == StatusFinished {
+			logGuess(at)
+		}
+		r.Record(GameEvent{Type: EventPhaseChanged, At: at, Status: p.Status})
+		if i != last[p.Status] {
+			continue
+		}
+		logDepartures(p.Status, false)
+
+		switch p.Status {
+		case StatusReadyCheck:
+			logReady(g.ReadyToReveal, at)
+		case StatusRoleReveal:
+			logReady(g.ReadyAfterReveal, at)
+		case StatusPlaying:
+			logReady(g.ReadyToVote, at)
+			// Accusations called off; the first resumes the clock where it is now
+			live := g.Trial != nil && g.Trial.Live()
+			resumeAt := g.PlayStartedAt
+			for _, id := range slices.Sorted(maps.Keys(g.Accusations)) {
+				n := g.Accusations[id]
+				if live && id == g.Trial.Accuser {
+					n--
+				}
+				for range n {
+					r.Record(GameEvent{Type: EventAccusationMade, At: at, Player: id})
+					r.Record(GameEvent{Type: EventPlayResumed, At: resumeAt})
+					resumeAt = at
+				}
+			}
+			if live {
+				r.Record(GameEvent{Type: EventAccusationMade, At: g.PausedAt, Player: g.Trial.Accuser, Target: g.Trial.Accused})
+				logVerdicts(g.Trial, g.PausedAt)
+			}
+		case StatusVoting:
+			for _, votes := range g.PastVotes {
+				logBallots(votes, nil, at)
+				r.Record(GameEvent{Type: EventRevoteStarted, At: at})
+			}
+			logBallots(g.Votes, g.VoteOrder, at)
+			if t := g.Trial; t != nil && !t.Live() {
+				r.Record(GameEvent{Type: EventTrialStarted, At: at, Target: t.Accused})
+				logVerdicts(t, at)
+			}
+		case StatusFinished:
+			r.Record(GameEvent{Type: EventGameFinished, At: at, InnocentWon: g.InnocentWon, Tally: g.Tally})
+			if g.Awards != nil {
+				r.Record(GameEvent{Type: EventPointsAwarded, At: at, Awards: g.Awards})
+			}
+			for _, owner := range slices.Sorted(maps.Keys(g.ChallengeReviews)) {
+				reviews := g.ChallengeReviews[owner]
+				for _, reviewer := range slices.Sorted(maps.Keys(reviews)) {
+					r.Record(GameEvent{Type: EventChallengeReviewed, At: at, Player: reviewer, Target: owner, Completed: reviews[reviewer]})
+				}
+			}
+		}
+	}
+	logWords(start)
+	logSetup(start)
+	logGuess(start)
+	logDepartures("", true)
+	return r.Log
+}
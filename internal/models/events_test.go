@@ -0,0 +1,205 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 1, 2, 18, 0, 0, 0, time.UTC)
+
+// at returns the time n seconds into the test game
+func at(n int) time.Time {
+	return t0.Add(time.Duration(n) * time.Second)
+}
+
+// playedGame records a whole game: an accusation that fails, a tied vote, a
+// revote decided by a trial, a departure, the result and a challenge review
+func playedGame() *Game {
+	settings := LobbySettings{VotingSystem: VotingAccusation, Accusations: 2}.Normalized(3)
+	g := &Game{}
+	g.Record(GameEvent{Type: EventGameCreated, At: at(0), Settings: &settings, Seed: 42})
+	for _, id := range []string{"ann", "ben", "cat", "dan", "eve"} {
+		g.Record(GameEvent{Type: EventPlayerJoined, At: at(0), Player: id, Name: id})
+	}
+	g.Record(GameEvent{Type: EventSetupDrawn, At: at(1), Setup: &GameSetup{
+		Input:         &SetupInput{Players: map[string]string{"ann": "Ann", "ben": "Ben", "cat": "Cat", "dan": "Dan", "eve": "Eve"}},
+		Location:      &Location{Word: "bank"},
+		Spies:         map[string]string{"eve": "eve"},
+		Challenges:    map[string]string{"ann": "hum", "ben": "wink", "cat": "yawn", "dan": "shrug", "eve": "whisper"},
+		QuestionOrder: []string{"cat", "ann"},
+	}})
+	events := []GameEvent{
+		{Type: EventPhaseChanged, At: at(1), Status: StatusReadyCheck},
+		{Type: EventReadyToggled, At: at(2), Player: "ann", Ready: true},
+		{Type: EventPhaseChanged, At: at(3), Status: StatusRoleReveal},
+		{Type: EventReadyToggled, At: at(4), Player: "ben", Ready: true},
+		{Type: EventPhaseChanged, At: at(5), Status: StatusPlaying},
+		{Type: EventAccusationMade, At: at(60), Player: "ann", Target: "ben"},
+		{Type: EventVerdictCast, At: at(65), Player: "cat", Guilty: false},
+		{Type: EventPlayResumed, At: at(70)},
+		{Type: EventReadyToggled, At: at(100), Player: "cat", Ready: true},
+		{Type: EventPhaseChanged, At: at(110), Status: StatusVoting},
+		{Type: EventVoteCast, At: at(111), Player: "ann", Ballot: Ballot{"eve"}},
+		{Type: EventVoteCast, At: at(112), Player: "ben", Ballot: Ballot{"dan"}},
+		{Type: EventRevoteStarted, At: at(113)},
+		{Type: EventVoteCast, At: at(114), Player: "ben", Ballot: Ballot{"eve"}},
+		{Type: EventVoteCast, At: at(115), Player: "ann", Ballot: Ballot{"eve"}},
+		{Type: EventVoteCast, At: at(116), Player: "ben", Ballot: Ballot{"eve"}},
+		{Type: EventPlayerLeft, At: at(117), Player: "dan"},
+		{Type: EventTrialStarted, At: at(118), Target: "eve"},
+		{Type: EventVerdictCast, At: at(119), Player: "ann", Guilty: true},
+		{Type: EventPhaseChanged, At: at(120), Status: StatusFinished},
+		{Type: EventGameFinished, At: at(120), InnocentWon: true, Tally: &VoteResult{MostVoted: "eve", InnocentWon: true, VoteCount: map[string]int{"eve": 3}}},
+		{Type: EventPointsAwarded, At: at(120), Awards: map[string][]PointAward{"ann": {{Rule: "caught_spy", Points: 2}}}},
+		{Type: EventChallengeReviewed, At: at(130), Player: "ben", Target: "ann", Completed: true},
+	}
+	for _, e := range events {
+		g.Record(e)
+	}
+	return g
+}
+
+func TestFoldGameRebuildsRecordedGame(t *testing.T) {
+	live := playedGame()
+
+	folded, err := FoldGame(live.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(folded, live) {
+		t.Errorf("folded game differs from the live one:\n got %+v\nwant %+v", folded, live)
+	}
+
+	// As restored from a snapshot
+	data, err := json.Marshal(live.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log []GameEvent
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := FoldGame(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored.Log = live.Log
+	if !reflect.DeepEqual(restored, live) {
+		t.Errorf("game restored from JSON differs from the live one:\n got %+v\nwant %+v", restored, live)
+	}
+}
+
+func TestApplyTracksPlay(t *testing.T) {
+	g := playedGame()
+
+	if g.FirstQuestioner != "cat" {
+		t.Errorf("first questioner = %q, want cat", g.FirstQuestioner)
+	}
+	// Stopped for 10 seconds by the accusation
+	if want := at(15); !g.PlayStartedAt.Equal(want) {
+		t.Errorf("play started at %v, want %v", g.PlayStartedAt, want)
+	}
+	if g.Accusations["ann"] != 1 {
+		t.Errorf("ann's accusations = %d, want 1", g.Accusations["ann"])
+	}
+	if g.VoteRound != 2 || len(g.PastVotes) != 1 || len(g.PastVotes[0]) != 2 {
+		t.Errorf("round %d with past votes %v, want round 2 after one round of 2 ballots", g.VoteRound, g.PastVotes)
+	}
+	if want := []string{"ben", "ann"}; !reflect.DeepEqual(g.VoteOrder, want) {
+		t.Errorf("vote order = %v, want %v", g.VoteOrder, want)
+	}
+	if _, ok := g.PlayerInfo["dan"]; ok || len(g.Departures) != 1 || g.Departures[0].Challenge != "shrug" {
+		t.Errorf("dan's departure not tracked: %+v", g.Departures)
+	}
+	if g.Trial == nil || !g.Trial.Guilty() || g.Trial.Accused != "eve" {
+		t.Errorf("trial = %+v, want eve found guilty", g.Trial)
+	}
+	if !g.InnocentWon || g.Status != StatusFinished || !g.ChallengeReviews["ann"]["ben"] {
+		t.Errorf("finished game not recorded: innocent won %v, status %s, reviews %v", g.InnocentWon, g.Status, g.ChallengeReviews)
+	}
+}
+
+func TestFoldGameRequiresGameCreated(t *testing.T) {
+	log := playedGame().Log[1:]
+	if _, err := FoldGame(log); err == nil {
+		t.Error("folded a log that doesn't open with game_created")
+	}
+}
+
+func TestLogFromStateKeepsGameFromBeforeTheLog(t *testing.T) {
+	// A game saved before settings, phase timing and the question order
+	old := &Game{
+		Mode:            GameModeStandard,
+		Location:        &Location{Word: "zoo"},
+		Spies:           map[string]string{"ben": "Ben"},
+		FirstQuestioner: "cat",
+		PlayerInfo: map[string]*GamePlayerInfo{
+			"ann": {Challenge: "hum"},
+			"ben": {Challenge: "wink", IsSpy: true},
+			"cat": {Challenge: "yawn"},
+		},
+		Status:        StatusPlaying,
+		PlayStartedAt: at(30),
+		ReadyToVote:   map[string]bool{"ann": true},
+		VoteRound:     1,
+	}
+
+	g, err := FoldGame(LogFromState(old, map[string]string{"ann": "Ann", "ben": "Ben", "cat": "Cat"}, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (LobbySettings{Mode: GameModeStandard}).Normalized(3); !reflect.DeepEqual(g.Settings, want) {
+		t.Errorf("settings = %+v, want %+v", g.Settings, want)
+	}
+	if !g.PlayStartedAt.Equal(old.PlayStartedAt) {
+		t.Errorf("play started at %v, want %v", g.PlayStartedAt, old.PlayStartedAt)
+	}
+	if g.FirstQuestioner != "cat" {
+		t.Errorf("first questioner = %q, want cat", g.FirstQuestioner)
+	}
+	if g.Status != StatusPlaying || !g.IsSpy("ben") || g.Location.Word != "zoo" || !g.ReadyToVote["ann"] {
+		t.Errorf("game not restored: %+v", g)
+	}
+	if len(g.PlayerInfo) != 3 || g.PlayerInfo["ann"].Challenge != "hum" {
+		t.Errorf("players = %v", g.PlayerInfo)
+	}
+}
+
+func TestLogFromStateKeepsVotesAndPauses(t *testing.T) {
+	live := playedGame()
+	// Snapshot it mid-vote, before the trial
+	var log []GameEvent
+	for _, e := range live.Log {
+		if e.Type == EventPlayerLeft {
+			break
+		}
+		log = append(log, e)
+	}
+	state, err := FoldGame(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Log = nil
+
+	g, err := FoldGame(LogFromState(state, nil, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.PlayStartedAt.Equal(state.PlayStartedAt) || g.FirstQuestioner != state.FirstQuestioner {
+		t.Errorf("play started at %v by %q, want %v by %q", g.PlayStartedAt, g.FirstQuestioner, state.PlayStartedAt, state.FirstQuestioner)
+	}
+	if !reflect.DeepEqual(g.Settings, state.Settings) {
+		t.Errorf("settings = %+v, want %+v", g.Settings, state.Settings)
+	}
+	if !reflect.DeepEqual(g.Votes, state.Votes) || !reflect.DeepEqual(g.VoteOrder, state.VoteOrder) {
+		t.Errorf("votes = %v in order %v, want %v in order %v", g.Votes, g.VoteOrder, state.Votes, state.VoteOrder)
+	}
+	if !reflect.DeepEqual(g.PastVotes, state.PastVotes) || g.VoteRound != state.VoteRound {
+		t.Errorf("round %d after %v, want round %d after %v", g.VoteRound, g.PastVotes, state.VoteRound, state.PastVotes)
+	}
+	if !reflect.DeepEqual(g.Phases, state.Phases) || !reflect.DeepEqual(g.Accusations, state.Accusations) {
+		t.Errorf("phases %v with accusations %v, want %v with %v", g.Phases, g.Accusations, state.Phases, state.Accusations)
+	}
+}
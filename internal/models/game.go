@@ -10,16 +10,22 @@ GameModeStandard    GameMode = "standard"    // Auto-select word from places.jso
 GameModeCustomWords GameMode = "custom_words" // Players submit custom words
 )
 
-// Game represents an active game session (ephemeral)
+// Game represents an active game session (ephemeral). Change it only through
+// Record so its log stays complete.
 type Game struct {
 Mode            GameMode
+Settings        LobbySettings // the lobby settings this game started with
+Seed            int64         // the random setup (location, spies, challenges, question order) is drawn from this
+Setup           *SetupInput   // what the setup drew from, so it can be replayed
+QuestionOrder   []string      // first questioner candidates; the first still present asks first
 Location        *Location
-SpyID           string
-SpyName         string                     // Store spy name in case they leave
+Spies           map[string]string          // spy player ID -> name (kept in case they leave)
 FirstQuestioner string                     // Player ID of who asks the first question
 PlayerInfo      map[string]*GamePlayerInfo // game-specific player data
 Status          GameStatus
-PlayStartedAt   time.Time // When the Playing phase started (for timer sync)
+PlayStartedAt   time.Time // When the Playing phase started (for timer sync), moved forward by pauses
+PausedAt        time.Time // When play was stopped for an accusation; zero while the clock runs
+PhaseStartedAt  time.Time // When the current Status was entered
 
 // Custom Words Mode fields
 CustomWords        map[string]string // playerID -> submitted word
@@ -29,7 +35,29 @@ WordsSubmitted     map[string]bool   // playerID -> has submitted word
 ReadyToReveal    map[string]bool // Phase 1: Ready to see role (all players required)
 ReadyAfterReveal map[string]bool // Phase 2: Confirmed saw role (all players required)
 ReadyToVote      map[string]bool // Phase 3: Ready to vote (>50% required)
-Votes            map[string]string
-VoteRound        int  // Track voting rounds for tie-breaking
-SpyForfeited     bool // True if spy left the game
+Votes            map[string]Ballot
+VoteOrder        []string // voters of the current round, in the order they cast the ballot they have now
+VoteRound        int      // Track voting rounds for tie-breaking
+PastVotes        []map[string]Ballot // ballots of earlier, undecided voting rounds
+Trial            *Trial   // the accused awaiting verdicts: a live accusation, or accusation voting's nominee
+Accusations      map[string]int // playerID -> live accusations made this round
+SpyForfeited     bool     // True if spy left the game
+SpyGuess         *LocationGuess // set if a spy ended play by guessing the location
+
+Phases     []PhaseTiming // every phase entered, in order
+Departures []Departure   // players who left during the game
+
+// Set when the game finishes
+InnocentWon      bool
+Tally            *VoteResult                // the final count; results, scoring and stats all read it
+Awards           map[string][]PointAward    // playerID -> points earned
+ChallengeReviews map[string]map[string]bool // challenge owner ID -> reviewer ID -> completed
+
+Log []GameEvent // every change so far, in order; the fields above are derived from it
+}
+
+// IsSpy reports whether playerID is one of the game's spies
+func (g *Game) IsSpy(playerID string) bool {
+_, ok := g.Spies[playerID]
+return ok
 }
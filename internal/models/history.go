@@ -0,0 +1,56 @@
+package models
+
+import "maps"
+
+// LobbyHistory remembers what the lobby's earlier games used, so later games
+// can rotate the spy role and avoid repeating locations and challenges
+type LobbyHistory struct {
+	Games      int             // games started in the lobby
+	LastSpy    map[string]int  // playerID -> number of the last game they were a spy in
+	Locations  map[string]bool // location words already played
+	Challenges map[string]bool // challenges already handed out
+}
+
+// StartGame counts a new game and returns its number, starting at 1
+func (h *LobbyHistory) StartGame() int {
+	h.Games++
+	return h.Games
+}
+
+// GamesSinceSpy is how many games ago playerID was last a spy, counting the
+// current game, so last game's spy gets 1; players who never were get Games
+func (h *LobbyHistory) GamesSinceSpy(playerID string) int {
+	return h.Games - h.LastSpy[playerID]
+}
+
+// RecordSpy notes that playerID is a spy in the current game
+func (h *LobbyHistory) RecordSpy(playerID string) {
+	if h.LastSpy == nil {
+		h.LastSpy = make(map[string]int)
+	}
+	h.LastSpy[playerID] = h.Games
+}
+
+// RecordLocation notes that word has been played
+func (h *LobbyHistory) RecordLocation(word string) {
+	if h.Locations == nil {
+		h.Locations = make(map[string]bool)
+	}
+	h.Locations[word] = true
+}
+
+// RecordChallenge notes that challenge has been handed out
+func (h *LobbyHistory) RecordChallenge(challenge string) {
+	if h.Challenges == nil {
+		h.Challenges = make(map[string]bool)
+	}
+	h.Challenges[challenge] = true
+}
+
+// Clone returns a deep copy of h
+func (h LobbyHistory) Clone() LobbyHistory {
+	h.LastSpy = maps.Clone(h.LastSpy)
+	h.Locations = maps.Clone(h.Locations)
+	h.Challenges = maps.Clone(h.Challenges)
+	return h
+}
@@ -1,16 +1,43 @@
 package models
 
-import "sync"
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
 
 // Lobby represents a persistent game lobby
 type Lobby struct {
 	Code        string
 	Host        string
+	CoHosts     map[string]bool         // players with the host's privileges, picked by the host
+	HostVotes   map[string]string       // voter ID -> player they want as host
 	Players     map[string]*Player      // playerID -> Player
 	Scores      map[string]*PlayerScore // playerID -> PlayerScore (persistent)
 	CurrentGame *Game                   // nil when in lobby
+	Language    string                  // UI and game content language, chosen by the host
+	Settings    LobbySettings           // host-chosen rules, kept across games
+	Series      *Series                 // nil unless the host started a series of games
+	History     LobbyHistory            // spies, locations and challenges of earlier games
+	Archive     []GameRecord            // finished games, oldest first
+	CreatedAt   time.Time
 	mu          sync.RWMutex
-	sseClients  map[chan SSEMessage]string // channel -> playerID
+	sseClients  map[chan SSEMessage]sseClient
+}
+
+// sseClient records who owns an SSE channel and when it connected
+type sseClient struct {
+	playerID    string
+	connectedAt time.Time
+}
+
+// SSEClientInfo describes a connected SSE client for status reporting
+type SSEClientInfo struct {
+	PlayerID    string
+	ConnectedAt time.Time
 }
 
 // SSEMessage represents a message sent via Server-Sent Events
@@ -39,21 +66,97 @@ func (l *Lobby) RUnlock() {
 	l.mu.RUnlock()
 }
 
+// PlayerIDs returns the IDs of the players in the lobby ordered by name (which
+// is unique in a lobby), so anything drawn from them in order is reproducible,
+// even on another run where the same names got different IDs (must be called with lock held)
+func (l *Lobby) PlayerIDs() []string {
+	return slices.SortedFunc(maps.Keys(l.Players), func(a, b string) int {
+		return cmp.Or(strings.Compare(l.Players[a].Name, l.Players[b].Name), strings.Compare(a, b))
+	})
+}
+
+// CanHost reports whether playerID has host privileges: the host or a co-host (must be called with lock held)
+func (l *Lobby) CanHost(playerID string) bool {
+	return playerID != "" && (l.Host == playerID || l.CoHosts[playerID])
+}
+
+// SetHost makes playerID the host, dropping their co-host role and any pending
+// host votes, which were cast against the previous host (must be called with lock held)
+func (l *Lobby) SetHost(playerID string) {
+	l.Host = playerID
+	delete(l.CoHosts, playerID)
+	l.HostVotes = nil
+}
+
+// SetCoHost grants or revokes playerID's co-host role (must be called with lock held)
+func (l *Lobby) SetCoHost(playerID string, coHost bool) {
+	if !coHost {
+		delete(l.CoHosts, playerID)
+		return
+	}
+	if l.CoHosts == nil {
+		l.CoHosts = make(map[string]bool)
+	}
+	l.CoHosts[playerID] = true
+}
+
+// VoteForHost records voterID's wish to make candidateID the host and returns
+// the candidate's vote count (must be called with lock held)
+func (l *Lobby) VoteForHost(voterID, candidateID string) int {
+	if l.HostVotes == nil {
+		l.HostVotes = make(map[string]string)
+	}
+	l.HostVotes[voterID] = candidateID
+	return l.HostVoteCount(candidateID)
+}
+
+// HostVoteCount returns how many players want candidateID as host (must be called with lock held)
+func (l *Lobby) HostVoteCount(candidateID string) int {
+	count := 0
+	for _, c := range l.HostVotes {
+		if c == candidateID {
+			count++
+		}
+	}
+	return count
+}
+
+// ForgetPlayer drops a departed player's co-host role and host votes, both
+// the ones they cast and the ones cast for them (must be called with lock held)
+func (l *Lobby) ForgetPlayer(playerID string) {
+	delete(l.CoHosts, playerID)
+	delete(l.HostVotes, playerID)
+	for voter, candidate := range l.HostVotes {
+		if candidate == playerID {
+			delete(l.HostVotes, voter)
+		}
+	}
+}
+
 // GetSSEClients returns a copy of the SSE clients map (must be called with lock held)
 func (l *Lobby) GetSSEClients() map[chan SSEMessage]string {
 	clients := make(map[chan SSEMessage]string, len(l.sseClients))
 	for k, v := range l.sseClients {
-		clients[k] = v
+		clients[k] = v.playerID
 	}
 	return clients
 }
 
+// SSEClientInfos returns details of every connected SSE client (must be called with lock held)
+func (l *Lobby) SSEClientInfos() []SSEClientInfo {
+	infos := make([]SSEClientInfo, 0, len(l.sseClients))
+	for _, c := range l.sseClients {
+		infos = append(infos, SSEClientInfo{PlayerID: c.playerID, ConnectedAt: c.connectedAt})
+	}
+	return infos
+}
+
 // AddSSEClient adds a new SSE client to the lobby
 func (l *Lobby) AddSSEClient(client chan SSEMessage, playerID string) {
 	if l.sseClients == nil {
-		l.sseClients = make(map[chan SSEMessage]string)
+		l.sseClients = make(map[chan SSEMessage]sseClient)
 	}
-	l.sseClients[client] = playerID
+	l.sseClients[client] = sseClient{playerID: playerID, connectedAt: time.Now()}
 }
 
 // RemoveSSEClient removes an SSE client from the lobby
@@ -5,3 +5,10 @@ type Location struct {
 	Word       string   `json:"word"`
 	Categories []string `json:"categories"`
 }
+
+// LocationGuess is a spy naming the location during play, which ends the game
+type LocationGuess struct {
+	Spy     string // player ID
+	Word    string
+	Correct bool
+}
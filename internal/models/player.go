@@ -1,15 +1,10 @@
 package models
 
-// PlayerScore tracks persistent score across games
-type PlayerScore struct {
-	GamesWon  int
-	GamesLost int
-}
-
 // Player represents a player in the lobby
 type Player struct {
-	ID   string
-	Name string
+	ID        string
+	Name      string
+	AccountID string // set when the player joined signed in
 }
 
 // GamePlayerInfo contains game-specific player information
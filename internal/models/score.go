@@ -0,0 +1,58 @@
+package models
+
+// PlayerScore tracks a player's results across the lobby's games
+type PlayerScore struct {
+	GamesWon  int
+	GamesLost int
+	Points    int
+	Spy       RoleRecord // games played as a spy
+	Innocent  RoleRecord // games played as an innocent
+}
+
+// RoleRecord counts wins and losses in one role
+type RoleRecord struct {
+	Won  int
+	Lost int
+}
+
+// PointAward is points a scoring rule gave a player in one game
+type PointAward struct {
+	Rule   string // the rule's key
+	Points int
+}
+
+// Record adds one finished game to the score
+func (s *PlayerScore) Record(wasSpy, won bool, awards []PointAward) {
+	role := &s.Innocent
+	if wasSpy {
+		role = &s.Spy
+	}
+	if won {
+		s.GamesWon++
+		role.Won++
+	} else {
+		s.GamesLost++
+		role.Lost++
+	}
+	s.Points += TotalPoints(awards)
+}
+
+// Since returns the score gained after base was recorded
+func (s *PlayerScore) Since(base PlayerScore) *PlayerScore {
+	return &PlayerScore{
+		GamesWon:  s.GamesWon - base.GamesWon,
+		GamesLost: s.GamesLost - base.GamesLost,
+		Points:    s.Points - base.Points,
+		Spy:       RoleRecord{Won: s.Spy.Won - base.Spy.Won, Lost: s.Spy.Lost - base.Spy.Lost},
+		Innocent:  RoleRecord{Won: s.Innocent.Won - base.Innocent.Won, Lost: s.Innocent.Lost - base.Innocent.Lost},
+	}
+}
+
+// TotalPoints sums awards
+func TotalPoints(awards []PointAward) int {
+	total := 0
+	for _, a := range awards {
+		total += a.Points
+	}
+	return total
+}
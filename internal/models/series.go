@@ -0,0 +1,90 @@
+package models
+
+import (
+	"maps"
+	"slices"
+)
+
+// Limits for a series of games
+const (
+	MinSeriesRounds = 2
+	MaxSeriesRounds = 10
+)
+
+// Series is a fixed number of games played back to back. Standings are the
+// lobby scores gained since the series started.
+type Series struct {
+	Rounds   int                    // games in the series
+	Round    int                    // the current (or last) game, starting at 1
+	Baseline map[string]PlayerScore // playerID -> lobby score when the series started
+	SpyTurns map[string]int         // playerID -> games played as a spy in this series
+}
+
+// NewSeries starts a series of rounds games from the lobby's current scores (lobby lock must be held)
+func NewSeries(rounds int, scores map[string]*PlayerScore) *Series {
+	s := &Series{
+		Rounds:   rounds,
+		Baseline: make(map[string]PlayerScore),
+		SpyTurns: make(map[string]int),
+	}
+	for id, score := range scores {
+		if score != nil {
+			s.Baseline[id] = *score
+		}
+	}
+	return s
+}
+
+// MinRounds is the shortest series in which every one of players gets to be a spy
+func MinRounds(players, spiesPerGame int) int {
+	return max(MinSeriesRounds, (players+spiesPerGame-1)/spiesPerGame)
+}
+
+// IsLastRound reports whether the current game is the series' final one
+func (s *Series) IsLastRound() bool {
+	return s.Round >= s.Rounds
+}
+
+// Standings returns each player's score gained during the series
+func (s *Series) Standings(scores map[string]*PlayerScore) map[string]*PlayerScore {
+	standings := make(map[string]*PlayerScore, len(scores))
+	for id, score := range scores {
+		if score == nil {
+			continue
+		}
+		standings[id] = score.Since(s.Baseline[id])
+	}
+	return standings
+}
+
+// Winners returns the IDs of the players with the most series points, wins
+// breaking ties; several players share the title on a full tie
+func (s *Series) Winners(scores map[string]*PlayerScore) []string {
+	var winners []string
+	var best PlayerScore
+	for id, score := range s.Standings(scores) {
+		switch {
+		case winners == nil || score.Points > best.Points || (score.Points == best.Points && score.GamesWon > best.GamesWon):
+			winners, best = []string{id}, *score
+		case score.Points == best.Points && score.GamesWon == best.GamesWon:
+			winners = append(winners, id)
+		}
+	}
+	slices.Sort(winners)
+	return winners
+}
+
+// ByTurns groups ids by how often they have been a spy in the series, fewest
+// turns first, so spies can be picked from the first group before the next
+func (s *Series) ByTurns(ids []string) [][]string {
+	byTurns := make(map[int][]string)
+	for _, id := range ids {
+		byTurns[s.SpyTurns[id]] = append(byTurns[s.SpyTurns[id]], id)
+	}
+	turns := slices.Sorted(maps.Keys(byTurns))
+	groups := make([][]string, 0, len(turns))
+	for _, t := range turns {
+		groups = append(groups, byTurns[t])
+	}
+	return groups
+}
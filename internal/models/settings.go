@@ -0,0 +1,71 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Limits for the host-editable lobby settings
+const (
+	MaxSpyCount              = 3
+	DefaultDiscussionMinutes = 10
+	MaxDiscussionMinutes     = 30
+	MaxVoteRoundsLimit       = 5
+	DefaultAccusations       = 1
+	MaxAccusations           = 3
+)
+
+// LobbySettings are the rules the host picks for the lobby's games. They live on
+// the lobby, so they carry over from one game to the next, and each game keeps a
+// copy of the settings it started with. Zero values mean "use the default".
+type LobbySettings struct {
+	Mode              GameMode
+	SpyCount          int
+	DiscussionMinutes int      // length of the playing phase timer
+	Categories        []string // locations are drawn from these categories; empty means all
+	MaxVoteRounds     int      // tie revotes before the spy wins; 0 means the server default
+	NoChallenges      bool     // play without secret challenges
+	AnonymousVoting   bool     // hide who voted for whom on the results page
+	NoChallengeBonus  bool     // challenges confirmed by the other players earn no points
+	LockVotes         bool     // a cast vote or verdict is final; otherwise it can change until everyone has voted
+	VotingSystem      VotingSystem
+	Accusations       int  // accusations each player may make while playing, per round
+	NoAccusations     bool // play without accusations
+}
+
+// Normalized returns s with defaults filled in and every value clamped to its limits
+func (s LobbySettings) Normalized(defaultVoteRounds int) LobbySettings {
+	if s.Mode != GameModeCustomWords {
+		s.Mode = GameModeStandard
+	}
+	if !slices.Contains(VotingSystems, s.VotingSystem) {
+		s.VotingSystem = VotingPlurality
+	}
+	s.SpyCount = clamp(s.SpyCount, 1, MaxSpyCount)
+	if s.DiscussionMinutes == 0 {
+		s.DiscussionMinutes = DefaultDiscussionMinutes
+	}
+	s.DiscussionMinutes = clamp(s.DiscussionMinutes, 1, MaxDiscussionMinutes)
+	if s.MaxVoteRounds == 0 {
+		s.MaxVoteRounds = defaultVoteRounds
+	}
+	s.MaxVoteRounds = clamp(s.MaxVoteRounds, 1, max(MaxVoteRoundsLimit, defaultVoteRounds))
+	switch {
+	case s.NoAccusations:
+		s.Accusations = 0
+	case s.Accusations == 0:
+		s.Accusations = DefaultAccusations
+	default:
+		s.Accusations = clamp(s.Accusations, 1, MaxAccusations)
+	}
+	return s
+}
+
+// DiscussionTime is the playing phase duration
+func (s LobbySettings) DiscussionTime() time.Duration {
+	return time.Duration(s.DiscussionMinutes) * time.Minute
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
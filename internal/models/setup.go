@@ -0,0 +1,21 @@
+package models
+
+// SetupInput is the lobby state a game's random setup drew from. Together with
+// the game's seed it reproduces the setup exactly.
+type SetupInput struct {
+	Players     map[string]string // player ID -> name
+	Language    string
+	History     LobbyHistory   // before the setup
+	SeriesTurns map[string]int // spy turns earlier in the series; nil outside one
+}
+
+// GameSetup is what a game's setup drew. It is logged whole, so rebuilding the
+// game from its log doesn't depend on the content files or random draws.
+type GameSetup struct {
+	Input         *SetupInput       `json:"input"`
+	Location      *Location         `json:"location"`
+	CustomWord    string            `json:"custom_word,omitempty"`
+	Spies         map[string]string `json:"spies"`      // spy player ID -> name
+	Challenges    map[string]string `json:"challenges"` // player ID -> challenge ("" when playing without); every player in the game
+	QuestionOrder []string          `json:"question_order"`
+}
@@ -0,0 +1,67 @@
+package models
+
+// VotingSystem is how the final vote decides who is voted out
+type VotingSystem string
+
+const (
+	VotingPlurality  VotingSystem = "plurality"  // one suspect each; the most votes is voted out
+	VotingApproval   VotingSystem = "approval"   // approve any number of suspects; the most approvals is voted out
+	VotingRanked     VotingSystem = "ranked"     // rank suspects; the fewest first choices are eliminated until someone has a majority
+	VotingAccusation VotingSystem = "accusation" // one suspect each; the most named is put on trial and needs a unanimous guilty verdict
+)
+
+// VotingSystems lists the voting systems in the order the settings show them
+var VotingSystems = []VotingSystem{VotingPlurality, VotingApproval, VotingRanked, VotingAccusation}
+
+// Ballot is one player's vote: the suspects they picked, in order of preference
+// where the voting system has one
+type Ballot []string
+
+// VoteResult is the outcome of counting a voting round or a live accusation.
+// The count that finished a game is kept on it as its tally.
+type VoteResult struct {
+	MostVoted      string // voted out; "" when the round was undecided
+	IsTie          bool
+	Acquitted      bool   // accusation voting: the accused wasn't found guilty unanimously
+	Accused        string // accusation voting: to be put on trial before anyone is voted out
+	InnocentWon    bool
+	VoteCount      map[string]int  // votes, approvals or final runoff votes per suspect
+	VotedCorrectly map[string]bool // voter ID -> their ballot named the spies
+	Eliminated     []string        // ranked voting: suspects dropped in the runoff, in order
+}
+
+// Undecided reports whether nobody was voted out this round
+func (r *VoteResult) Undecided() bool {
+	return r.IsTie || r.Acquitted
+}
+
+// Trial is an accused player awaiting the other players' verdicts
+type Trial struct {
+	Accused  string
+	Accuser  string          // set when a player stopped play to accuse; empty for a voting round's trial
+	Verdicts map[string]bool // voter ID -> guilty
+}
+
+// Live reports whether the trial interrupted play rather than ending a voting round
+func (t *Trial) Live() bool {
+	return t.Accuser != ""
+}
+
+// Guilty reports whether every verdict so far is guilty (and there is at least one)
+func (t *Trial) Guilty() bool {
+	for _, guilty := range t.Verdicts {
+		if !guilty {
+			return false
+		}
+	}
+	return len(t.Verdicts) > 0
+}
+
+// VoteProgress returns how many of the players have voted in the current
+// round and how many need to. During a trial the accused doesn't vote.
+func (g *Game) VoteProgress(players int) (int, int) {
+	if g.Trial != nil {
+		return len(g.Trial.Verdicts), players - 1
+	}
+	return len(g.Votes), players
+}
@@ -20,22 +20,23 @@ func getPlayerList(players map[string]*models.Player) []*models.Player {
 // GetPlayerList is the exported version of getPlayerList for use by handlers
 var GetPlayerList = getPlayerList
 
-// GetPlayerListSortedByScore returns players sorted by wins descending, then name ascending
+// GetPlayerListSortedByScore returns the leaderboard order: points descending,
+// then wins descending, then name ascending
 func GetPlayerListSortedByScore(players map[string]*models.Player, scores map[string]*models.PlayerScore) []*models.Player {
 	list := getPlayerList(players)
-	// Sort by wins descending, then name asc
-	sort.SliceStable(list, func(i, j int) bool {
-		wi, wj := 0, 0
-		if s, ok := scores[list[i].ID]; ok {
-			wi = s.GamesWon
-		}
-		if s, ok := scores[list[j].ID]; ok {
-			wj = s.GamesWon
+	score := func(p *models.Player) models.PlayerScore {
+		if s, ok := scores[p.ID]; ok && s != nil {
+			return *s
 		}
-		if wi == wj {
-			return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+		return models.PlayerScore{}
+	}
+	// list is already sorted by name, so a stable sort keeps ties alphabetical
+	sort.SliceStable(list, func(i, j int) bool {
+		si, sj := score(list[i]), score(list[j])
+		if si.Points != sj.Points {
+			return si.Points > sj.Points
 		}
-		return wi > wj
+		return si.GamesWon > sj.GamesWon
 	})
 	return list
 }
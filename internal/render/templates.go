@@ -0,0 +1,86 @@
+package render
+
+import (
+	"html/template"
+	"io"
+	"io/fs"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
+)
+
+// funcs are the helpers available to every template
+var funcs = template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+	"div": func(a, b int) int { return a / b },
+}
+
+// Templates holds the parsed page templates and partials, one set per language.
+// In reload mode the files are re-parsed on every execution so edits show up without a restart.
+type Templates struct {
+	fsys     fs.FS
+	reload   bool
+	basePath string
+	catalog  *i18n.Catalog
+	sets     map[string]*template.Template // language -> templates
+}
+
+// NewTemplates parses *.html and partials/*.html from fsys.
+// basePath is exposed to templates as {{basePath}} for building links,
+// {{t "key" args...}} translates a catalog message and {{lang}} is the page language.
+// Parsing always happens once up front so broken templates fail at startup.
+func NewTemplates(fsys fs.FS, reload bool, basePath string, catalog *i18n.Catalog) (*Templates, error) {
+	t := &Templates{fsys: fsys, reload: reload, basePath: basePath, catalog: catalog}
+	base, err := t.parse()
+	if err != nil {
+		return nil, err
+	}
+	t.sets = make(map[string]*template.Template)
+	for _, l := range catalog.Languages() {
+		if t.sets[l.Code], err = t.localize(base, l.Code); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// ExecuteTemplate renders the named template to w in lang (the default language if unsupported)
+func (t *Templates) ExecuteTemplate(w io.Writer, lang, name string, data any) error {
+	if !t.catalog.Supports(lang) {
+		lang = i18n.DefaultLanguage
+	}
+	tmpl := t.sets[lang]
+	if t.reload {
+		base, err := t.parse()
+		if err != nil {
+			return err
+		}
+		if tmpl, err = t.localize(base, lang); err != nil {
+			return err
+		}
+	}
+	return tmpl.ExecuteTemplate(w, name, data)
+}
+
+func (t *Templates) parse() (*template.Template, error) {
+	return template.New("").
+		Funcs(funcs).
+		Funcs(template.FuncMap{
+			"basePath": func() string { return t.basePath },
+			// Placeholders so the files parse; localize binds the real ones
+			"t":    func(key string, args ...any) string { return key },
+			"lang": func() string { return i18n.DefaultLanguage },
+		}).
+		ParseFS(t.fsys, "*.html", "partials/*.html")
+}
+
+// localize clones the parsed templates with t and lang bound to one language
+func (t *Templates) localize(base *template.Template, lang string) (*template.Template, error) {
+	clone, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Funcs(template.FuncMap{
+		"t":    func(key string, args ...any) string { return t.catalog.T(lang, key, args...) },
+		"lang": func() string { return lang },
+	}), nil
+}
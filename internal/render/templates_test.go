@@ -0,0 +1,209 @@
+package render
+
+import (
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// The repo checkout holds the same files the binary embeds (dev mode reads it too)
+var (
+	templateFS = os.DirFS("../../templates")
+	localesFS  = os.DirFS("../../data/locales")
+)
+
+// gameView mirrors the view model handlers build for every game phase page
+type gameView struct {
+	RoomCode        string
+	PlayerID        string
+	Status          models.GameStatus
+	Players         []*models.Player
+	TotalPlayers    int
+	Location        *models.Location
+	Challenge       string
+	IsSpy           bool
+	IsReady         bool
+	HasVoted        bool
+	VoteRound       int
+	VotingSystem    models.VotingSystem
+	Suspects        []*models.Player
+	Accused         string
+	Accuser         string
+	IsAccused       bool
+	HasVerdict      bool
+	ChangePath      string
+	Changing        bool
+	AccusationsLeft int
+	GuessOptions    []string
+	FirstQuestioner string
+	PlayStartedAt   int64
+	PausedAt        int64
+	DurationSeconds int
+	SpyCount        int
+	IsHost          bool
+	CanHost         bool
+	MinPlayers      int
+}
+
+// pageViews are zero-value copies of the view models the handlers render each
+// page with. The view models themselves are private to the handlers, so these
+// mirror their fields; slices of handler-only types stay empty, so their
+// element type doesn't matter. Pointers the handlers always set point at zero values.
+var pageViews = map[string]any{
+	"admin.html": struct {
+		Ready      bool
+		NotReady   string
+		Lobbies    []any
+		Players    int
+		SSEClients int
+		Locations  int
+		Challenges int
+	}{},
+	"error.html": struct {
+		Status  int
+		Title   string
+		Message string
+	}{},
+	"game_confirm_reveal.html": gameView{Location: &models.Location{}},
+	"game_roles.html":          gameView{Location: &models.Location{}},
+	"game_play.html":           gameView{Location: &models.Location{}},
+	"game_voting.html":         gameView{Location: &models.Location{}},
+	"game_word_collection.html": struct {
+		RoomCode            string
+		PlayerID            string
+		Players             []*models.Player
+		TotalPlayers        int
+		HasSubmittedWord    bool
+		SubmittedWord       string
+		WordsSubmittedCount int
+		WordsSubmitted      map[string]bool
+		IsHost              bool
+		CanHost             bool
+	}{},
+	"history.html": struct {
+		RoomCode string
+		Games    []models.GameRecord
+	}{},
+	"index.html": struct {
+		MinPlayers     int
+		RoomCodeLength int
+		Nickname       string
+	}{},
+	"join_lobby.html": struct {
+		RoomCode string
+	}{},
+	"lobby.html": struct {
+		RoomCode      string
+		PlayerID      string
+		Players       []*models.Player
+		IsHost        bool
+		Scores        map[string]*models.PlayerScore
+		HasResults    bool
+		HostID        string
+		CoHosts       map[string]bool
+		QRCodeDataURL template.URL
+		HostControls  struct {
+			IsHost           bool
+			IsMainHost       bool
+			PlayerCount      int
+			InGame           bool
+			RoomCode         string
+			HostName         string
+			MinPlayers       int
+			Language         string
+			Languages        []i18n.Language
+			Settings         models.LobbySettings
+			Categories       []any
+			Members          []any
+			MyHostVote       string
+			VotesNeeded      int
+			SpyCounts        []int
+			DiscussionLimits []int
+			VoteRoundLimits  []int
+			VotingSystems    []models.VotingSystem
+			AccusationLimits []int
+			SeriesRounds     []int
+		}
+		ArchivedGames int
+	}{},
+	"results.html": struct {
+		RoomCode        string
+		PlayerID        string
+		IsHost          bool
+		CanHost         bool
+		Players         []*models.Player
+		Spies           []any
+		IsSpy           map[string]bool
+		Location        *models.Location
+		AnonymousVoting bool
+		VotingSystem    models.VotingSystem
+		VoteCount       map[string]int
+		Votes           struct {
+			Ballots    []any
+			Eliminated []string
+			Trial      *struct{}
+		}
+		VoteRounds   int
+		MostVoted    string
+		IsTie        bool
+		InnocentWon  bool
+		SpyForfeited bool
+		Guess        *struct{}
+		Reviews      struct {
+			RoomCode       string
+			ShowChallenges bool
+			BonusPoints    bool
+			Challenges     []any
+			Players        []*models.Player
+			Awards         map[string][]models.PointAward
+			PointsEarned   map[string]int
+		}
+		Series *struct{}
+	}{Location: &models.Location{}},
+	"select_host.html": struct {
+		RoomCode     string
+		OtherPlayers []any
+	}{},
+	"stats.html": struct {
+		SignedIn          bool
+		Account           models.Account
+		FavoriteLocations []models.LocationCount
+		SignInLink        string
+	}{},
+}
+
+// TestPagesExecute parses the templates and renders every page in every
+// language with an empty view model, so a page that only breaks once it is
+// executed fails here rather than on a player's screen
+func TestPagesExecute(t *testing.T) {
+	catalog, err := i18n.Load(localesFS)
+	if err != nil {
+		t.Fatalf("loading catalogs: %v", err)
+	}
+	templates, err := NewTemplates(templateFS, false, "", catalog)
+	if err != nil {
+		t.Fatalf("parsing templates: %v", err)
+	}
+	pages, err := fs.Glob(templateFS, "*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, page := range pages {
+		view, ok := pageViews[page]
+		if !ok {
+			t.Errorf("%s: no view model to render it with", page)
+			continue
+		}
+		for _, l := range catalog.Languages() {
+			if err := templates.ExecuteTemplate(io.Discard, l.Code, page, view); err != nil {
+				t.Errorf("%s (%s): %v", page, l.Code, err)
+			}
+		}
+	}
+}
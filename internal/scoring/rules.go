@@ -0,0 +1,126 @@
+package scoring
+
+import (
+	"slices"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// SpySurvives rewards every spy when the spies win
+type SpySurvives struct{ Points int }
+
+func (SpySurvives) Key() string { return "spy_survived" }
+
+func (r SpySurvives) Award(o Outcome) map[string]int {
+	points := make(map[string]int)
+	if o.InnocentWon {
+		return points
+	}
+	for _, id := range o.Players {
+		if o.Game.IsSpy(id) {
+			points[id] = r.Points
+		}
+	}
+	return points
+}
+
+// SpyGuessedLocation rewards the spy who ended play by naming the location
+type SpyGuessedLocation struct{ Points int }
+
+func (SpyGuessedLocation) Key() string { return "spy_guessed_location" }
+
+func (r SpyGuessedLocation) Award(o Outcome) map[string]int {
+	points := make(map[string]int)
+	if guess := o.Game.SpyGuess; guess != nil && guess.Correct && slices.Contains(o.Players, guess.Spy) {
+		points[guess.Spy] = r.Points
+	}
+	return points
+}
+
+// InnocentsWin rewards every innocent when a spy is caught or forfeits
+type InnocentsWin struct{ Points int }
+
+func (InnocentsWin) Key() string { return "innocents_won" }
+
+func (r InnocentsWin) Award(o Outcome) map[string]int {
+	points := make(map[string]int)
+	if !o.InnocentWon {
+		return points
+	}
+	for _, id := range o.Players {
+		if !o.Game.IsSpy(id) {
+			points[id] = r.Points
+		}
+	}
+	return points
+}
+
+// CorrectVote rewards innocents whose final vote named the spies, win or lose.
+// A forfeit ends the game before the vote is complete, so it scores nothing;
+// neither does an anonymous vote, since the points would reveal it.
+type CorrectVote struct{ Points int }
+
+func (CorrectVote) Key() string { return "correct_vote" }
+
+func (r CorrectVote) Award(o Outcome) map[string]int {
+	points := make(map[string]int)
+	if o.Game.SpyForfeited || o.Game.Settings.AnonymousVoting {
+		return points
+	}
+	for _, id := range o.Players {
+		if o.VotedCorrectly[id] && !o.Game.IsSpy(id) {
+			points[id] = r.Points
+		}
+	}
+	return points
+}
+
+// FirstAccuser rewards the first innocent to vote for a spy in the final round
+// with the ballot they kept, so changing a vote counts from when it changed
+// (skipped for forfeits and anonymous votes, like CorrectVote). When a live
+// accusation convicted a spy, the innocent who stopped play to make it was first;
+// that is public, so it counts even when voting is anonymous.
+type FirstAccuser struct{ Points int }
+
+func (FirstAccuser) Key() string { return "first_accuser" }
+
+func (r FirstAccuser) Award(o Outcome) map[string]int {
+	points := make(map[string]int)
+	if o.Game.SpyForfeited {
+		return points
+	}
+	if t := o.Game.Trial; t != nil && t.Live() {
+		if t.Guilty() && o.Game.IsSpy(t.Accused) && !o.Game.IsSpy(t.Accuser) && slices.Contains(o.Players, t.Accuser) {
+			points[t.Accuser] = r.Points
+		}
+		return points
+	}
+	if o.Game.Settings.AnonymousVoting {
+		return points
+	}
+	for _, id := range o.Game.VoteOrder {
+		if !o.Game.IsSpy(id) && o.VotedCorrectly[id] {
+			points[id] = r.Points
+			break
+		}
+	}
+	return points
+}
+
+// ChallengeCompleted rewards players whose challenge the others confirmed on the results page
+type ChallengeCompleted struct{ Points int }
+
+func (ChallengeCompleted) Key() string { return "challenge_completed" }
+
+func (r ChallengeCompleted) Award(o Outcome) map[string]int {
+	points := make(map[string]int)
+	if o.Game.Settings.NoChallenges || o.Game.Settings.NoChallengeBonus {
+		return points
+	}
+	for _, id := range o.Players {
+		if o.Game.ChallengeVerdict(id, o.Players) == models.VerdictConfirmed {
+			points[id] = r.Points
+		}
+	}
+	return points
+}
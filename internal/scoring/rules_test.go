@@ -0,0 +1,175 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+var players = []string{"ann", "ben", "cat", "eve"}
+
+// finishedGame returns a game with eve as the spy in which votes were cast in
+// order, each as a voter followed by their suspect
+func finishedGame(settings models.LobbySettings, votes ...string) *models.Game {
+	g := models.NewGame(settings.Normalized(3), 1)
+	g.Record(models.GameEvent{Type: models.EventSetupDrawn, Setup: &models.GameSetup{
+		Location:   &models.Location{Word: "bank"},
+		Spies:      map[string]string{"eve": "Eve"},
+		Challenges: map[string]string{"ann": "hum", "ben": "wink", "cat": "yawn", "eve": "shrug"},
+	}})
+	g.Record(models.GameEvent{Type: models.EventPhaseChanged, Status: models.StatusVoting})
+	for i := 0; i < len(votes); i += 2 {
+		g.Record(models.GameEvent{Type: models.EventVoteCast, Player: votes[i], Ballot: models.Ballot{votes[i+1]}})
+	}
+	return g
+}
+
+// accusedGame returns a game with eve as the spy in which accuser stopped play
+// to accuse accused and the others gave verdicts, each as a player followed by
+// "guilty" or "not guilty"
+func accusedGame(settings models.LobbySettings, accuser, accused string, verdicts ...string) *models.Game {
+	g := finishedGame(settings)
+	g.Record(models.GameEvent{Type: models.EventPhaseChanged, Status: models.StatusPlaying})
+	g.Record(models.GameEvent{Type: models.EventAccusationMade, Player: accuser, Target: accused})
+	for i := 0; i < len(verdicts); i += 2 {
+		g.Record(models.GameEvent{Type: models.EventVerdictCast, Player: verdicts[i], Guilty: verdicts[i+1] == "guilty"})
+	}
+	return g
+}
+
+// outcome is what the rules see of g, with correct votes naming eve
+func outcome(g *models.Game, innocentWon bool) Outcome {
+	correct := make(map[string]bool)
+	for voter, ballot := range g.Votes {
+		correct[voter] = g.IsSpy(ballot[0])
+	}
+	return Outcome{Game: g, Players: players, InnocentWon: innocentWon, VotedCorrectly: correct}
+}
+
+func TestRules(t *testing.T) {
+	caught := finishedGame(models.LobbySettings{}, "ann", "eve", "ben", "cat", "cat", "eve", "eve", "ann")
+	forfeited := finishedGame(models.LobbySettings{}, "ann", "eve")
+	forfeited.SpyForfeited = true
+	anonymous := finishedGame(models.LobbySettings{AnonymousVoting: true}, "ann", "eve", "ben", "eve")
+	guessed := finishedGame(models.LobbySettings{})
+	guessed.SpyGuess = &models.LocationGuess{Spy: "eve", Word: "bank", Correct: true}
+	// ann switched to eve only after ben had already named her
+	switched := finishedGame(models.LobbySettings{}, "ann", "cat", "ben", "eve", "ann", "eve")
+	resubmitted := finishedGame(models.LobbySettings{}, "ann", "eve", "ben", "eve", "ann", "eve")
+	convicted := accusedGame(models.LobbySettings{}, "cat", "eve", "ann", "guilty", "ben", "guilty")
+	convictedAnonymously := accusedGame(models.LobbySettings{AnonymousVoting: true}, "cat", "eve", "ann", "guilty", "ben", "guilty")
+	wronglyConvicted := accusedGame(models.LobbySettings{}, "cat", "ben", "ann", "guilty", "eve", "guilty")
+
+	tests := []struct {
+		name    string
+		rule    Rule
+		outcome Outcome
+		want    map[string]int
+	}{
+		{"spy survives", SpySurvives{Points: 4}, outcome(caught, false), map[string]int{"eve": 4}},
+		{"spy caught", SpySurvives{Points: 4}, outcome(caught, true), map[string]int{}},
+		{"spy guessed the location", SpyGuessedLocation{Points: 2}, outcome(guessed, false), map[string]int{"eve": 2}},
+		{"no guess", SpyGuessedLocation{Points: 2}, outcome(caught, true), map[string]int{}},
+		{"innocents win", InnocentsWin{Points: 1}, outcome(caught, true), map[string]int{"ann": 1, "ben": 1, "cat": 1}},
+		{"innocents lose", InnocentsWin{Points: 1}, outcome(caught, false), map[string]int{}},
+		{"correct votes", CorrectVote{Points: 1}, outcome(caught, false), map[string]int{"ann": 1, "cat": 1}},
+		{"correct votes after a forfeit", CorrectVote{Points: 1}, outcome(forfeited, true), map[string]int{}},
+		{"anonymous correct votes", CorrectVote{Points: 1}, outcome(anonymous, true), map[string]int{}},
+		{"first accuser", FirstAccuser{Points: 1}, outcome(caught, true), map[string]int{"ann": 1}},
+		{"first accuser after a forfeit", FirstAccuser{Points: 1}, outcome(forfeited, true), map[string]int{}},
+		{"anonymous first accuser", FirstAccuser{Points: 1}, outcome(anonymous, true), map[string]int{}},
+		{"first accuser after a changed vote", FirstAccuser{Points: 1}, outcome(switched, true), map[string]int{"ben": 1}},
+		{"first accuser resubmitting the same vote", FirstAccuser{Points: 1}, outcome(resubmitted, true), map[string]int{"ann": 1}},
+		{"first accuser convicting the spy during play", FirstAccuser{Points: 1}, outcome(convicted, true), map[string]int{"cat": 1}},
+		{"first accuser convicting the spy during anonymous play", FirstAccuser{Points: 1}, outcome(convictedAnonymously, true), map[string]int{"cat": 1}},
+		{"first accuser convicting an innocent during play", FirstAccuser{Points: 1}, outcome(wronglyConvicted, false), map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Award(tt.outcome); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s Award() = %v, want %v", tt.rule.Key(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChallengeCompleted(t *testing.T) {
+	g := finishedGame(models.LobbySettings{}, "ann", "eve")
+	rule := ChallengeCompleted{Points: 2}
+	review := func(reviewer string, completed bool) {
+		g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: reviewer, Target: "ann", Completed: completed})
+	}
+
+	review("ben", true)
+	if got := rule.Award(outcome(g, true)); len(got) != 0 {
+		t.Errorf("one of three confirmations awarded %v", got)
+	}
+	review("cat", true)
+	if got, want := rule.Award(outcome(g, true)), map[string]int{"ann": 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("two of three confirmations awarded %v, want %v", got, want)
+	}
+	// A reviewer changing their mind takes the points back
+	review("cat", false)
+	review("eve", false)
+	if got := rule.Award(outcome(g, true)); len(got) != 0 {
+		t.Errorf("disputed challenge awarded %v", got)
+	}
+
+	g.Settings.NoChallengeBonus = true
+	review("cat", true)
+	review("eve", true)
+	if got := rule.Award(outcome(g, true)); len(got) != 0 {
+		t.Errorf("challenge bonus turned off but awarded %v", got)
+	}
+}
+
+func TestRescoreAfterReview(t *testing.T) {
+	engine := Default()
+	g := finishedGame(models.LobbySettings{}, "ann", "eve", "ben", "eve", "cat", "ann")
+	before := engine.Score(outcome(g, true))
+
+	g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: "ben", Target: "cat", Completed: true})
+	g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: "eve", Target: "cat", Completed: true})
+	after := engine.Score(outcome(g, true))
+
+	for _, id := range players {
+		gained := models.TotalPoints(after[id]) - models.TotalPoints(before[id])
+		want := 0
+		if id == "cat" {
+			want = 2
+		}
+		if gained != want {
+			t.Errorf("%s gained %d points from the review, want %d", id, gained, want)
+		}
+	}
+	if want := (models.PointAward{Rule: "challenge_completed", Points: 2}); !reflect.DeepEqual(after["cat"][len(after["cat"])-1], want) {
+		t.Errorf("cat's awards = %v, want the challenge bonus last", after["cat"])
+	}
+}
+
+func TestRescoreAfterLiveAccusation(t *testing.T) {
+	engine := Default()
+	g := accusedGame(models.LobbySettings{}, "cat", "eve", "ann", "guilty", "ben", "guilty")
+	o := outcome(g, true)
+	o.VotedCorrectly = map[string]bool{"ann": true, "ben": true, "cat": true}
+	before := engine.Score(o)
+
+	g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: "ann", Target: "cat", Completed: true})
+	g.Record(models.GameEvent{Type: models.EventChallengeReviewed, Player: "ben", Target: "cat", Completed: true})
+	after := engine.Score(o)
+
+	want := []models.PointAward{
+		{Rule: "innocents_won", Points: 1},
+		{Rule: "correct_vote", Points: 1},
+		{Rule: "first_accuser", Points: 1},
+	}
+	if !reflect.DeepEqual(before["cat"], want) {
+		t.Errorf("accuser's awards = %v, want %v", before["cat"], want)
+	}
+	want = append(want, models.PointAward{Rule: "challenge_completed", Points: 2})
+	if !reflect.DeepEqual(after["cat"], want) {
+		t.Errorf("accuser's awards after the review = %v, want %v", after["cat"], want)
+	}
+}
@@ -0,0 +1,57 @@
+// Package scoring turns a finished game into points using a configurable set of rules.
+package scoring
+
+import "github.com/aaronzipp/you-are-officially-sus/internal/models"
+
+// Outcome is what the rules get to see of a finished game
+type Outcome struct {
+	Game        *models.Game
+	Players     []string // IDs of the players still in the lobby
+	InnocentWon bool
+	// VotedCorrectly marks the voters whose final ballot named the spies, as
+	// the game's voting system judges it
+	VotedCorrectly map[string]bool
+}
+
+// Rule awards points for one aspect of a finished game
+type Rule interface {
+	// Key identifies the rule; the results page shows it via the "scoring.<key>" message
+	Key() string
+	// Award returns the points each player earns from this rule (players without points may be omitted)
+	Award(o Outcome) map[string]int
+}
+
+// Engine scores games by applying its rules in order
+type Engine struct {
+	rules []Rule
+}
+
+// New creates an engine with the given rules
+func New(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Default is the standard rule set
+func Default() *Engine {
+	return New(
+		SpySurvives{Points: 4},
+		SpyGuessedLocation{Points: 2},
+		InnocentsWin{Points: 1},
+		CorrectVote{Points: 1},
+		FirstAccuser{Points: 1},
+		ChallengeCompleted{Points: 2},
+	)
+}
+
+// Score returns every player's point awards for a finished game
+func (e *Engine) Score(o Outcome) map[string][]models.PointAward {
+	awards := make(map[string][]models.PointAward)
+	for _, rule := range e.rules {
+		for id, points := range rule.Award(o) {
+			if points != 0 {
+				awards[id] = append(awards[id], models.PointAward{Rule: rule.Key(), Points: points})
+			}
+		}
+	}
+	return awards
+}
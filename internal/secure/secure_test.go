@@ -0,0 +1,193 @@
+package secure
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/config"
+)
+
+func TestIsHTTPS(t *testing.T) {
+	tests := []struct {
+		name       string
+		tls        bool
+		proto      string
+		trustProxy bool
+		want       bool
+	}{
+		{name: "plain", want: false},
+		{name: "direct TLS", tls: true, want: true},
+		{name: "direct TLS, proxy trusted", tls: true, trustProxy: true, want: true},
+		{name: "forwarded https, proxy trusted", proto: "https", trustProxy: true, want: true},
+		{name: "forwarded HTTPS, proxy trusted", proto: "HTTPS", trustProxy: true, want: true},
+		{name: "forwarded chain, proxy trusted", proto: " https , http", trustProxy: true, want: true},
+		{name: "forwarded http, proxy trusted", proto: "http", trustProxy: true, want: false},
+		{name: "forwarded https, proxy untrusted", proto: "https", want: false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		if tt.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+		if got := IsHTTPS(r, tt.trustProxy); got != tt.want {
+			t.Errorf("%s: IsHTTPS = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHSTS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		tls    bool
+		want   string
+	}{
+		{name: "secure request", maxAge: 24 * time.Hour, tls: true, want: "max-age=86400"},
+		{name: "plain request", maxAge: 24 * time.Hour, want: ""},
+		{name: "disabled", maxAge: 0, tls: true, want: ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		w := httptest.NewRecorder()
+		HSTS(ok, tt.maxAge, false).ServeHTTP(w, r)
+		if got := w.Header().Get("Strict-Transport-Security"); got != tt.want {
+			t.Errorf("%s: Strict-Transport-Security = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		httpsAddr string
+		target    string
+		want      string
+	}{
+		{httpsAddr: ":443", target: "http://example.com/lobby/ABCD?x=1", want: "https://example.com/lobby/ABCD?x=1"},
+		{httpsAddr: ":443", target: "http://example.com:8080/", want: "https://example.com/"},
+		{httpsAddr: ":8443", target: "http://example.com:8080/game/ABCD", want: "https://example.com:8443/game/ABCD"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		RedirectHandler(tt.httpsAddr).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s via %s: status %d, want %d", tt.target, tt.httpsAddr, w.Code, http.StatusPermanentRedirect)
+		}
+		if got := w.Header().Get("Location"); got != tt.want {
+			t.Errorf("%s via %s: redirected to %q, want %q", tt.target, tt.httpsAddr, got, tt.want)
+		}
+	}
+}
+
+func TestRedirectPassesACMEChallenges(t *testing.T) {
+	m, err := NewACMEManager(config.TLSConfig{ACMEDomains: []string{"example.com"}, ACMECacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Served the way main wires the plain HTTP listener
+	h := m.HTTPHandler(RedirectHandler(":443"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/.well-known/acme-challenge/token", nil))
+	if w.Code == http.StatusPermanentRedirect {
+		t.Errorf("challenge request was redirected to %q", w.Header().Get("Location"))
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/lobby/ABCD", nil))
+	if got := w.Header().Get("Location"); got != "https://example.com/lobby/ABCD" {
+		t.Errorf("other requests redirected to %q", got)
+	}
+}
+
+func TestNewACMEManager(t *testing.T) {
+	m, err := NewACMEManager(config.TLSConfig{ACMEDomains: []string{"example.com"}, ACMECacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Client != nil {
+		t.Errorf("default manager has a custom client for %q", m.Client.DirectoryURL)
+	}
+
+	cert, caFile := testCA(t)
+	const directory = "https://localhost:14000/dir"
+	m, err = NewACMEManager(config.TLSConfig{
+		ACMEDomains:      []string{"example.com"},
+		ACMECacheDir:     t.TempDir(),
+		ACMEDirectoryURL: directory,
+		ACMECAFile:       caFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Client == nil || m.Client.DirectoryURL != directory {
+		t.Fatalf("client = %+v, want directory %s", m.Client, directory)
+	}
+	transport, ok := m.Client.HTTPClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil {
+		t.Fatal("client doesn't use its own TLS config")
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: transport.TLSClientConfig.RootCAs}); err != nil {
+		t.Errorf("client doesn't trust the CA file: %v", err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewACMEManager(config.TLSConfig{ACMECAFile: empty}); err == nil {
+		t.Error("CA file without certificates accepted")
+	}
+	if _, err := NewACMEManager(config.TLSConfig{ACMECAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("missing CA file accepted")
+	}
+}
+
+// testCA writes a self-signed CA certificate to a PEM file and returns it with the file's path
+func testCA(t *testing.T) (*x509.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, path
+}
@@ -1,19 +1,22 @@
 package sse
 
 import (
-	"log"
+	"log/slog"
 	"maps"
-	"os"
 	"time"
 
 	"github.com/aaronzipp/you-are-officially-sus/internal/game"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/metrics"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
 )
 
-var debug bool
+// sendTimeout bounds how long a broadcast waits on one slow client
+var sendTimeout = time.Duration(game.SSETimeoutSeconds) * time.Second
 
-func init() {
-	debug = os.Getenv("DEBUG") != ""
+// SetSendTimeout overrides the per-client send timeout (call once at startup)
+func SetSendTimeout(d time.Duration) {
+	sendTimeout = d
 }
 
 // AddClient adds a new SSE client to the lobby
@@ -30,9 +33,10 @@ func AddClient(lobby *models.Lobby, client chan models.SSEMessage, playerID stri
 		}
 	}
 	if dup > 0 {
-		log.Printf("WARN: player %s opened %d additional SSE connection(s)", playerID, dup)
+		slog.Warn("Player opened additional SSE connections", logging.Room(lobby.Code), logging.Player(playerID), "additional", dup)
 	}
 	lobby.AddSSEClient(client, playerID)
+	metrics.SSEConnections.Inc()
 }
 
 // RemoveClient removes an SSE client from the lobby
@@ -40,7 +44,8 @@ func RemoveClient(lobby *models.Lobby, client chan models.SSEMessage) {
 	lobby.Lock()
 	defer lobby.Unlock()
 	lobby.RemoveSSEClient(client)
-	log.Printf("removeSSEClient: client removed, now have %d total clients", lobby.SSEClientCount())
+	metrics.SSEConnections.Dec()
+	slog.Debug("SSE client removed", logging.Room(lobby.Code), "clients", lobby.SSEClientCount())
 }
 
 // Broadcast sends a message to all connected SSE clients
@@ -51,26 +56,23 @@ func Broadcast(lobby *models.Lobby, event, data string) {
 	clientCount := len(clients)
 	lobby.RUnlock()
 
-	if debug {
-		log.Printf("broadcastSSE: event=%s to %d clients", event, clientCount)
-	}
+	slog.Debug("Broadcasting SSE event", logging.Room(lobby.Code), "event", event, "clients", clientCount)
 
 	// Send messages WITHOUT holding the lock
+	start := time.Now()
+	defer metrics.ObserveBroadcast(metrics.KindBroadcast, start)
 	msg := models.SSEMessage{Event: event, Data: data}
 	successCount := 0
 	for client := range clients {
 		select {
 		case client <- msg:
 			successCount++
-		case <-time.After(time.Duration(game.SSETimeoutSeconds) * time.Second):
-			if debug {
-				log.Printf("broadcastSSE: timeout sending to client")
-			}
+		case <-time.After(sendTimeout):
+			metrics.SSESendTimeouts.WithLabelValues(metrics.KindBroadcast).Inc()
+			slog.Debug("SSE broadcast timed out for client", logging.Room(lobby.Code), "event", event)
 		}
 	}
-	if debug {
-		log.Printf("broadcastSSE: sent to %d/%d clients successfully", successCount, clientCount)
-	}
+	slog.Debug("SSE broadcast delivered", logging.Room(lobby.Code), "event", event, "delivered", successCount, "clients", clientCount)
 }
 
 // BroadcastPersonalized sends personalized messages to each client
@@ -81,14 +83,17 @@ func BroadcastPersonalized(lobby *models.Lobby, renderFunc func(playerID string)
 	lobby.RUnlock()
 
 	// Send personalized messages WITHOUT holding the lock
+	start := time.Now()
+	defer metrics.ObserveBroadcast(metrics.KindPersonalized, start)
 	for client, playerID := range clientMap {
 		html := renderFunc(playerID)
 		msg := models.SSEMessage{Event: eventName, Data: html}
 		select {
 		case client <- msg:
 			// Message sent successfully
-		case <-time.After(time.Duration(game.SSETimeoutSeconds) * time.Second):
+		case <-time.After(sendTimeout):
 			// Timeout - skip this client to avoid blocking
+			metrics.SSESendTimeouts.WithLabelValues(metrics.KindPersonalized).Inc()
 		}
 	}
 }
@@ -100,19 +105,18 @@ func BroadcastToPlayer(lobby *models.Lobby, playerID, event, data string) {
 	clientMap := maps.Clone(lobby.GetSSEClients())
 	lobby.RUnlock()
 
+	start := time.Now()
+	defer metrics.ObserveBroadcast(metrics.KindPlayer, start)
 	msg := models.SSEMessage{Event: event, Data: data}
 	// Find all connections for this player and send the message
 	for client, pid := range clientMap {
 		if pid == playerID {
 			select {
 			case client <- msg:
-				if debug {
-					log.Printf("BroadcastToPlayer: sent event=%s to player %s", event, playerID)
-				}
-			case <-time.After(time.Duration(game.SSETimeoutSeconds) * time.Second):
-				if debug {
-					log.Printf("BroadcastToPlayer: timeout sending to player %s", playerID)
-				}
+				slog.Debug("SSE event sent to player", logging.Room(lobby.Code), logging.Player(playerID), "event", event)
+			case <-time.After(sendTimeout):
+				metrics.SSESendTimeouts.WithLabelValues(metrics.KindPlayer).Inc()
+				slog.Debug("SSE send to player timed out", logging.Room(lobby.Code), logging.Player(playerID), "event", event)
 			}
 		}
 	}
@@ -8,4 +8,5 @@ const (
 	EventVoteCount      = "vote-count-voting"
 	EventHostChanged    = "host-changed"
 	EventErrorMessage   = "error-message"
+	EventChallenges     = "challenge-reviews"
 )
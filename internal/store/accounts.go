@@ -0,0 +1,183 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/google/uuid"
+)
+
+// AccountsVersion is the current accounts file format version
+const AccountsVersion = 1
+
+// accountsFile is the on-disk layout of the account store
+type accountsFile struct {
+	Version  int               `json:"version"`
+	SavedAt  time.Time         `json:"saved_at"`
+	Accounts []*models.Account `json:"accounts"`
+}
+
+// AccountStore keeps player accounts and their stats. With a path every change
+// is written through to that file; without one accounts only live in memory.
+type AccountStore struct {
+	mu       sync.RWMutex
+	path     string
+	accounts map[string]*models.Account // ID -> account
+	byToken  map[string]string          // token hash -> ID
+}
+
+// NewAccountStore creates an account store backed by path (empty for memory only),
+// loading the accounts already saved there
+func NewAccountStore(path string) (*AccountStore, error) {
+	s := &AccountStore{
+		path:     path,
+		accounts: make(map[string]*models.Account),
+		byToken:  make(map[string]string),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file accountsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing accounts: %w", err)
+	}
+	if file.Version != AccountsVersion {
+		return nil, fmt.Errorf("unsupported accounts version %d (want %d)", file.Version, AccountsVersion)
+	}
+	for _, a := range file.Accounts {
+		s.accounts[a.ID] = a
+		s.byToken[a.TokenHash] = a.ID
+	}
+	return s, nil
+}
+
+// Create registers a new account and returns it with its sign-in token.
+// Only the token's hash is stored, so the token can't be recovered later.
+func (s *AccountStore) Create(nickname string) (models.Account, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return models.Account{}, "", err
+	}
+	token := hex.EncodeToString(raw)
+	account := &models.Account{
+		ID:        uuid.New().String(),
+		Nickname:  nickname,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[account.ID] = account
+	s.byToken[account.TokenHash] = account.ID
+	if err := s.save(); err != nil {
+		delete(s.accounts, account.ID)
+		delete(s.byToken, account.TokenHash)
+		return models.Account{}, "", err
+	}
+	return *account, token, nil
+}
+
+// Get returns a copy of the account with id. The copy shares nothing with the
+// store, so it can be read after the lock is released.
+func (s *AccountStore) Get(id string) (models.Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return models.Account{}, false
+	}
+	account := *a
+	account.Stats.Locations = maps.Clone(a.Stats.Locations)
+	return account, true
+}
+
+// ByToken returns a copy of the account a sign-in token belongs to
+func (s *AccountStore) ByToken(token string) (models.Account, bool) {
+	if token == "" {
+		return models.Account{}, false
+	}
+	s.mu.RLock()
+	id, ok := s.byToken[hashToken(token)]
+	s.mu.RUnlock()
+	if !ok {
+		return models.Account{}, false
+	}
+	return s.Get(id)
+}
+
+// RecordResults adds a finished game's results (account ID -> result) to the
+// accounts' stats. Unknown accounts are skipped.
+func (s *AccountStore) RecordResults(results map[string]models.GameResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, r := range results {
+		if a, ok := s.accounts[id]; ok {
+			a.Stats.Add(r)
+		}
+	}
+	return s.save()
+}
+
+// Count returns the number of accounts
+func (s *AccountStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.accounts)
+}
+
+// save writes every account to the store's file, via a temporary sibling and
+// a rename like SaveSnapshot (must be called with lock held)
+func (s *AccountStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	file := accountsFile{Version: AccountsVersion, SavedAt: time.Now().UTC()}
+	for _, a := range s.accounts {
+		file.Accounts = append(file.Accounts, a)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding accounts: %w", err)
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating accounts dir: %w", err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing accounts: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replacing accounts: %w", err)
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
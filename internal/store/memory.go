@@ -48,3 +48,32 @@ func (s *LobbyStore) Exists(code string) bool {
 	_, exists := s.lobbies[code]
 	return exists
 }
+
+// All returns a snapshot slice of every lobby currently in the store
+func (s *LobbyStore) All() []*models.Lobby {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lobbies := make([]*models.Lobby, 0, len(s.lobbies))
+	for _, lobby := range s.lobbies {
+		lobbies = append(lobbies, lobby)
+	}
+	return lobbies
+}
+
+// Count returns the number of lobbies in the store
+func (s *LobbyStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.lobbies)
+}
+
+// PlayerCount returns the total number of players across all lobbies
+func (s *LobbyStore) PlayerCount() int {
+	count := 0
+	for _, lobby := range s.All() {
+		lobby.RLock()
+		count += len(lobby.Players)
+		lobby.RUnlock()
+	}
+	return count
+}
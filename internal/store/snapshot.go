@@ -0,0 +1,332 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// SnapshotVersion is the current snapshot file format version.
+// Bump it whenever models change in a way older snapshots can't be decoded
+// into, and add a migration from the previous version.
+const SnapshotVersion = 4
+
+// migrations upgrade one encoded lobby from the version they are keyed by to
+// the next. They work on the raw JSON because older lobbies don't decode into
+// the current models. defaultVoteRounds is the server's vote round limit, for
+// games from before games kept their settings.
+var migrations = map[int]func(lobby map[string]any, defaultVoteRounds int){
+	1: migrateSettings,
+	2: migrateBallots,
+	3: migrateLog,
+}
+
+// snapshotFile is the on-disk layout of a store snapshot
+type snapshotFile struct {
+	Version int               `json:"version"`
+	SavedAt time.Time         `json:"saved_at"`
+	Lobbies []json.RawMessage `json:"lobbies"`
+}
+
+// SaveSnapshot writes all lobbies (players, scores, host and current game) to path as versioned JSON.
+// The file is written to a temporary sibling first and renamed so a crash never leaves a partial snapshot behind.
+func (s *LobbyStore) SaveSnapshot(path string) error {
+	snap := snapshotFile{Version: SnapshotVersion, SavedAt: time.Now().UTC()}
+	for _, lobby := range s.All() {
+		data, err := marshalLobby(lobby)
+		if err != nil {
+			return fmt.Errorf("encoding lobby %s: %w", lobby.Code, err)
+		}
+		snap.Lobbies = append(snap.Lobbies, data)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating snapshot dir: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replacing snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot restores lobbies from a snapshot written by SaveSnapshot.
+// Lobbies already in the store with the same code are replaced. Games from before
+// games kept their settings get the lobby's, or the defaults with defaultVoteRounds
+// vote rounds. Returns the number of lobbies restored.
+func (s *LobbyStore) LoadSnapshot(path string, defaultVoteRounds int) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, fmt.Errorf("parsing snapshot: %w", err)
+	}
+	if snap.Version < 1 || snap.Version > SnapshotVersion {
+		return 0, fmt.Errorf("unsupported snapshot version %d (want %d)", snap.Version, SnapshotVersion)
+	}
+
+	lobbies := make([]*models.Lobby, 0, len(snap.Lobbies))
+	for i, raw := range snap.Lobbies {
+		raw, err := migrateLobby(raw, snap.Version, defaultVoteRounds)
+		if err != nil {
+			return 0, fmt.Errorf("migrating lobby #%d: %w", i, err)
+		}
+		lobby, err := decodeLobby(raw)
+		if err != nil {
+			return 0, fmt.Errorf("decoding lobby #%d: %w", i, err)
+		}
+		if lobby.Code == "" {
+			return 0, fmt.Errorf("decoding lobby #%d: missing code", i)
+		}
+		normalizeLobby(lobby)
+		lobbies = append(lobbies, lobby)
+	}
+
+	for _, lobby := range lobbies {
+		s.Set(lobby.Code, lobby)
+	}
+	return len(lobbies), nil
+}
+
+// decodeLobby decodes one lobby. If only its game in progress can't be decoded,
+// the lobby is restored without it rather than failing the whole restore.
+func decodeLobby(raw json.RawMessage) (*models.Lobby, error) {
+	lobby := &models.Lobby{}
+	err := json.Unmarshal(raw, lobby)
+	if err == nil {
+		return lobby, nil
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil || fields["CurrentGame"] == nil {
+		return nil, err
+	}
+	delete(fields, "CurrentGame")
+	raw, _ = json.Marshal(fields)
+	lobby = &models.Lobby{}
+	if json.Unmarshal(raw, lobby) != nil {
+		return nil, err
+	}
+	slog.Warn("Dropped a game that couldn't be restored", "room", lobby.Code, "error", err)
+	return lobby, nil
+}
+
+// migrateLobby brings a lobby encoded by snapshot version from up to date
+func migrateLobby(raw json.RawMessage, from, defaultVoteRounds int) (json.RawMessage, error) {
+	if from == SnapshotVersion {
+		return raw, nil
+	}
+	// Numbers stay json.Number so seeds don't lose precision as float64
+	var lobby map[string]any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&lobby); err != nil {
+		return nil, err
+	}
+	for v := from; v < SnapshotVersion; v++ {
+		migrations[v](lobby, defaultVoteRounds)
+	}
+	return json.Marshal(lobby)
+}
+
+// migrateSettings (1 -> 2) brings games from before the lobby settings up to
+// date: the single SpyID/SpyName becomes the Spies map, and the game gets the
+// lobby's settings, or the defaults, so it keeps its timer and tie revotes
+func migrateSettings(lobby map[string]any, defaultVoteRounds int) {
+	g, ok := lobby["CurrentGame"].(map[string]any)
+	if !ok {
+		return
+	}
+	if id, ok := g["SpyID"].(string); ok && id != "" {
+		g["Spies"] = map[string]any{id: g["SpyName"]}
+	}
+	delete(g, "SpyID")
+	delete(g, "SpyName")
+
+	if g["Settings"] != nil {
+		return
+	}
+	var settings models.LobbySettings
+	if raw, ok := lobby["Settings"]; ok {
+		data, _ := json.Marshal(raw)
+		_ = json.Unmarshal(data, &settings)
+	}
+	settings = settings.Normalized(defaultVoteRounds)
+	if mode, ok := g["Mode"].(string); ok {
+		settings.Mode = models.GameMode(mode)
+	}
+	g["Settings"] = settings
+}
+
+// migrateBallots (2 -> 3) turns the single-suspect votes of games from before
+// the voting systems into one-suspect ballots: in the game's current and earlier
+// rounds, its vote events and the archived games
+func migrateBallots(lobby map[string]any, _ int) {
+	if g, ok := lobby["CurrentGame"].(map[string]any); ok {
+		toBallots(g["Votes"])
+		if past, ok := g["PastVotes"].([]any); ok {
+			for _, votes := range past {
+				toBallots(votes)
+			}
+		}
+		if log, ok := g["Log"].([]any); ok {
+			for _, e := range log {
+				e, ok := e.(map[string]any)
+				if !ok || e["type"] != string(models.EventVoteCast) || e["ballot"] != nil {
+					continue
+				}
+				if suspect, ok := e["target"].(string); ok && suspect != "" {
+					e["ballot"] = []any{suspect}
+					delete(e, "target")
+				}
+			}
+		}
+	}
+	archive, _ := lobby["Archive"].([]any)
+	for _, record := range archive {
+		record, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		rounds, _ := record["vote_rounds"].([]any)
+		for _, round := range rounds {
+			ballots, _ := round.([]any)
+			for _, b := range ballots {
+				if b, ok := b.(map[string]any); ok && b["suspects"] == nil {
+					if suspect, ok := b["suspect"].(string); ok {
+						b["suspects"] = []any{suspect}
+					}
+					delete(b, "suspect")
+				}
+			}
+		}
+	}
+}
+
+// toBallots rewrites a voter -> suspect map as voter -> [suspect]
+func toBallots(votes any) {
+	m, ok := votes.(map[string]any)
+	if !ok {
+		return
+	}
+	for voter, v := range m {
+		if suspect, ok := v.(string); ok {
+			m[voter] = []any{suspect}
+		}
+	}
+}
+
+// migrateLog (3 -> 4) gives a game from before the event log a log written
+// from its state, so what happens next is recorded after a game_created event
+func migrateLog(lobby map[string]any, defaultVoteRounds int) {
+	raw, ok := lobby["CurrentGame"].(map[string]any)
+	if !ok {
+		return
+	}
+	if log, _ := raw["Log"].([]any); len(log) > 0 {
+		return
+	}
+	data, _ := json.Marshal(raw)
+	var g models.Game
+	if json.Unmarshal(data, &g) != nil {
+		// Left for decodeLobby to drop
+		return
+	}
+	names := make(map[string]string)
+	players, _ := lobby["Players"].(map[string]any)
+	for id, p := range players {
+		if p, ok := p.(map[string]any); ok {
+			names[id], _ = p["Name"].(string)
+		}
+	}
+	raw["Log"] = models.LogFromState(&g, names, defaultVoteRounds)
+}
+
+// marshalLobby encodes a single lobby while holding its read lock
+func marshalLobby(lobby *models.Lobby) (json.RawMessage, error) {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	return json.Marshal(lobby)
+}
+
+// normalizeLobby re-creates maps that handlers expect to be non-nil after decoding
+func normalizeLobby(lobby *models.Lobby) {
+	if lobby.Players == nil {
+		lobby.Players = make(map[string]*models.Player)
+	}
+	if lobby.Scores == nil {
+		lobby.Scores = make(map[string]*models.PlayerScore)
+	}
+	for id := range lobby.Players {
+		if lobby.Scores[id] == nil {
+			lobby.Scores[id] = &models.PlayerScore{}
+		}
+	}
+
+	if lobby.Series != nil && lobby.Series.SpyTurns == nil {
+		lobby.Series.SpyTurns = make(map[string]int)
+	}
+
+	g := lobby.CurrentGame
+	if g == nil {
+		return
+	}
+	if len(g.Log) > 0 {
+		// The log is the source of truth; migrateLog wrote one for older games
+		folded, err := models.FoldGame(g.Log)
+		if err != nil {
+			slog.Warn("Dropped a game that couldn't be restored", "room", lobby.Code, "error", err)
+			lobby.CurrentGame = nil
+			return
+		}
+		lobby.CurrentGame = folded
+		return
+	}
+	if g.PlayerInfo == nil {
+		g.PlayerInfo = make(map[string]*models.GamePlayerInfo)
+	}
+	if g.ReadyToReveal == nil {
+		g.ReadyToReveal = make(map[string]bool)
+	}
+	if g.ReadyAfterReveal == nil {
+		g.ReadyAfterReveal = make(map[string]bool)
+	}
+	if g.ReadyToVote == nil {
+		g.ReadyToVote = make(map[string]bool)
+	}
+	if g.Votes == nil {
+		g.Votes = make(map[string]models.Ballot)
+	}
+	if g.Accusations == nil {
+		g.Accusations = make(map[string]int)
+	}
+	if g.Trial != nil && g.Trial.Verdicts == nil {
+		g.Trial.Verdicts = make(map[string]bool)
+	}
+	if g.Mode == models.GameModeCustomWords {
+		if g.CustomWords == nil {
+			g.CustomWords = make(map[string]string)
+		}
+		if g.WordsSubmitted == nil {
+			g.WordsSubmitted = make(map[string]bool)
+		}
+	}
+}
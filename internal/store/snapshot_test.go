@@ -0,0 +1,190 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+)
+
+// loadFixture restores a snapshot from testdata with 3 default vote rounds
+func loadFixture(t *testing.T, name string) *LobbyStore {
+	t.Helper()
+	s := NewLobbyStore()
+	if _, err := s.LoadSnapshot(filepath.Join("testdata", name), 3); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// restoredGame returns the current game of the lobby with code
+func restoredGame(t *testing.T, s *LobbyStore, code string) *models.Game {
+	t.Helper()
+	lobby, ok := s.Get(code)
+	if !ok {
+		t.Fatalf("lobby %s not restored", code)
+	}
+	if lobby.CurrentGame == nil {
+		t.Fatalf("lobby %s restored without its game", code)
+	}
+	return lobby.CurrentGame
+}
+
+// date parses an RFC 3339 time
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLoadSnapshotV1(t *testing.T) {
+	s := loadFixture(t, "snapshot_v1.json")
+
+	// Played before the lobby had settings: the defaults
+	g := restoredGame(t, s, "PLAYV1")
+	if want := (models.LobbySettings{}).Normalized(3); !reflect.DeepEqual(g.Settings, want) {
+		t.Errorf("settings = %+v, want %+v", g.Settings, want)
+	}
+	if g.Status != models.StatusPlaying || !g.PlayStartedAt.Equal(date("2026-01-02T18:05:00Z")) {
+		t.Errorf("status %s, play started at %v", g.Status, g.PlayStartedAt)
+	}
+	if g.FirstQuestioner != "cat" {
+		t.Errorf("first questioner = %q, want cat", g.FirstQuestioner)
+	}
+	if want := map[string]string{"ben": "Ben"}; !reflect.DeepEqual(g.Spies, want) {
+		t.Errorf("spies = %v, want %v", g.Spies, want)
+	}
+	if !g.ReadyToVote["ann"] || g.PlayerInfo["ben"].Challenge != "wink" || len(g.Log) == 0 {
+		t.Errorf("game not restored: %+v", g)
+	}
+	lobby, _ := s.Get("PLAYV1")
+	if lobby.Scores["ann"].GamesWon != 1 || lobby.Scores["cat"] == nil {
+		t.Errorf("scores = %v", lobby.Scores)
+	}
+
+	// The lobby's settings, with a tie over single-suspect votes
+	g = restoredGame(t, s, "VOTEV1")
+	if g.Settings.MaxVoteRounds != 4 || g.Settings.DiscussionMinutes != 5 || g.Settings.VotingSystem != models.VotingPlurality {
+		t.Errorf("settings = %+v, want the lobby's", g.Settings)
+	}
+	if g.Status != models.StatusVoting || g.VoteRound != 1 {
+		t.Errorf("status %s in round %d", g.Status, g.VoteRound)
+	}
+	if !g.PlayStartedAt.Equal(date("2026-01-02T17:50:00Z")) || !g.PhaseStartedAt.Equal(date("2026-01-02T18:00:00Z")) {
+		t.Errorf("play started at %v, voting at %v", g.PlayStartedAt, g.PhaseStartedAt)
+	}
+	if want := map[string]models.Ballot{"dan": {"eve"}, "fay": {"gus"}}; !reflect.DeepEqual(g.Votes, want) {
+		t.Errorf("votes = %v, want %v", g.Votes, want)
+	}
+	if !g.IsSpy("eve") || len(g.Spies) != 1 {
+		t.Errorf("spies = %v, want eve", g.Spies)
+	}
+}
+
+func TestLoadSnapshotV2(t *testing.T) {
+	s := loadFixture(t, "snapshot_v2.json")
+
+	g := restoredGame(t, s, "VOTEV2")
+	if g.Seed != 9007199254740993 {
+		t.Errorf("seed = %d, want 9007199254740993", g.Seed)
+	}
+	if g.Settings.MaxVoteRounds != 2 || g.Settings.DiscussionMinutes != 8 {
+		t.Errorf("settings = %+v", g.Settings)
+	}
+	if g.Status != models.StatusVoting || g.VoteRound != 2 {
+		t.Errorf("status %s in round %d", g.Status, g.VoteRound)
+	}
+	if !g.PlayStartedAt.Equal(date("2026-01-02T18:00:20Z")) || g.FirstQuestioner != "dan" {
+		t.Errorf("play started at %v by %q", g.PlayStartedAt, g.FirstQuestioner)
+	}
+	if want := map[string]models.Ballot{"ann": {"cat"}, "ben": {"dan"}}; !reflect.DeepEqual(g.Votes, want) {
+		t.Errorf("votes = %v, want %v", g.Votes, want)
+	}
+	if want := []map[string]models.Ballot{{"ann": {"ben"}, "ben": {"ann"}}}; !reflect.DeepEqual(g.PastVotes, want) {
+		t.Errorf("past votes = %v, want %v", g.PastVotes, want)
+	}
+	if want := map[string]string{"cat": "Cat"}; !reflect.DeepEqual(g.Spies, want) {
+		t.Errorf("spies = %v, want %v", g.Spies, want)
+	}
+
+	lobby, _ := s.Get("VOTEV2")
+	if len(lobby.Archive) != 1 || len(lobby.Archive[0].VoteRounds) != 1 {
+		t.Fatalf("archive = %+v", lobby.Archive)
+	}
+	want := []models.BallotRecord{{Voter: "Ann", Suspects: []string{"Ben"}}, {Voter: "Cat", Suspects: []string{"Ben"}}}
+	if got := lobby.Archive[0].VoteRounds[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("archived ballots = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadSnapshotV3(t *testing.T) {
+	s := loadFixture(t, "snapshot_v3.json")
+
+	g := restoredGame(t, s, "PLAYV3")
+	if g.Settings.VotingSystem != models.VotingRanked || g.Settings.DiscussionMinutes != 12 {
+		t.Errorf("settings = %+v", g.Settings)
+	}
+	if g.Status != models.StatusVoting || g.VoteRound != 1 {
+		t.Errorf("status %s in round %d", g.Status, g.VoteRound)
+	}
+	if !g.PlayStartedAt.Equal(date("2026-01-02T18:00:20Z")) || !g.PhaseStartedAt.Equal(date("2026-01-02T18:12:20Z")) {
+		t.Errorf("play started at %v, voting at %v", g.PlayStartedAt, g.PhaseStartedAt)
+	}
+	if want := map[string]models.Ballot{"cat": {"ben", "eve"}, "ann": {"eve"}}; !reflect.DeepEqual(g.Votes, want) {
+		t.Errorf("votes = %v, want %v", g.Votes, want)
+	}
+	if want := []string{"cat", "ann"}; !reflect.DeepEqual(g.VoteOrder, want) {
+		t.Errorf("vote order = %v, want %v", g.VoteOrder, want)
+	}
+	if !g.IsSpy("ben") || !g.IsSpy("eve") || len(g.Departures) != 1 || g.PlayerInfo["dan"] != nil {
+		t.Errorf("spies %v, departures %v", g.Spies, g.Departures)
+	}
+
+	// A log that doesn't open with game_created can't be folded; the lobby stays
+	lobby, ok := s.Get("BADLOG")
+	if !ok || lobby.CurrentGame != nil {
+		t.Errorf("lobby with an unreadable game: restored %v, game %+v", ok, lobby)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, name := range []string{"snapshot_v1.json", "snapshot_v2.json", "snapshot_v3.json"} {
+		migrated := loadFixture(t, name)
+		path := filepath.Join(t.TempDir(), "snapshot.json")
+		if err := migrated.SaveSnapshot(path); err != nil {
+			t.Fatal(err)
+		}
+		restored := NewLobbyStore()
+		if _, err := restored.LoadSnapshot(path, 3); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		for _, lobby := range migrated.All() {
+			again, ok := restored.Get(lobby.Code)
+			if !ok {
+				t.Errorf("%s: lobby %s lost", name, lobby.Code)
+				continue
+			}
+			if !reflect.DeepEqual(again.CurrentGame, lobby.CurrentGame) {
+				t.Errorf("%s: game of %s changed:\n got %+v\nwant %+v", name, lobby.Code, again.CurrentGame, lobby.CurrentGame)
+			}
+		}
+	}
+}
+
+func TestLoadSnapshotRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	data := fmt.Sprintf(`{"version": %d, "lobbies": []}`, SnapshotVersion+1)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLobbyStore().LoadSnapshot(path, 3); err == nil {
+		t.Error("loaded a snapshot from a newer version")
+	}
+}
@@ -1,115 +1,327 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"html/template"
-	"log"
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/aaronzipp/you-are-officially-sus/internal/config"
+	"github.com/aaronzipp/you-are-officially-sus/internal/game"
 	"github.com/aaronzipp/you-are-officially-sus/internal/handlers"
+	"github.com/aaronzipp/you-are-officially-sus/internal/i18n"
+	"github.com/aaronzipp/you-are-officially-sus/internal/logging"
+	"github.com/aaronzipp/you-are-officially-sus/internal/metrics"
 	"github.com/aaronzipp/you-are-officially-sus/internal/models"
+	"github.com/aaronzipp/you-are-officially-sus/internal/render"
+	"github.com/aaronzipp/you-are-officially-sus/internal/scoring"
+	"github.com/aaronzipp/you-are-officially-sus/internal/secure"
+	"github.com/aaronzipp/you-are-officially-sus/internal/sse"
 	"github.com/aaronzipp/you-are-officially-sus/internal/store"
 	"github.com/joho/godotenv"
 )
 
-var (
-	debug   bool
-	baseURL string
-)
-
 func init() {
 	// Load .env file if it exists (ignore error if file doesn't exist)
 	_ = godotenv.Load()
-
-	// Enable DEBUG logs when DEBUG env var is set (non-empty)
-	debug = os.Getenv("DEBUG") != ""
-
-	// Read BASE_URL from environment (empty if not set)
-	baseURL = os.Getenv("BASE_URL")
 }
 
 func main() {
-	// Load data
-	locations, challenges, err := loadData()
+	// Resolve configuration: defaults < config file < env < flags
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
 	if err != nil {
-		log.Fatal("Failed to load data:", err)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		os.Exit(2)
+	}
+	if err := logging.Setup(os.Stderr, cfg.Log.Level, cfg.Log.Format); err != nil {
+		fatal("Invalid logging configuration", "error", err)
 	}
+	sse.SetSendTimeout(cfg.Game.SSESendTimeout)
 
-	// Parse templates with custom functions
-	tmpl := template.New("").Funcs(template.FuncMap{
-		"add": func(a, b int) int { return a + b },
-	})
+	// Assets come from the embedded copy unless dev mode or a directory override points at disk
+	templateFS := assetFS(cfg.TemplatesDir, "templates", cfg.DevMode)
+	staticFS := assetFS(cfg.StaticDir, "static", cfg.DevMode)
+	dataFS := assetFS(cfg.DataDir, "data", cfg.DevMode)
+
+	// Load UI message catalogs, one per language
+	localesFS, err := fs.Sub(dataFS, "locales")
+	if err != nil {
+		fatal("Failed to open message catalogs", "error", err)
+	}
+	catalog, err := i18n.Load(localesFS)
+	if err != nil {
+		fatal("Failed to load message catalogs", "error", err)
+	}
 
-	// Parse main templates and partials
-	templates, err := tmpl.ParseGlob("templates/*.html")
+	// Load data
+	locations, challenges, err := loadData(dataFS, catalog.Languages())
 	if err != nil {
-		log.Fatal("Failed to parse templates:", err)
+		fatal("Failed to load data", "error", err)
 	}
-	templates, err = templates.ParseGlob("templates/partials/*.html")
+
+	// Parse templates and partials (re-parsed on every render in dev mode)
+	templates, err := render.NewTemplates(templateFS, cfg.DevMode, cfg.BasePath, catalog)
 	if err != nil {
-		log.Fatal("Failed to parse template partials:", err)
+		fatal("Failed to parse templates", "error", err)
+	}
+	if cfg.DevMode {
+		slog.Info("Dev mode: serving assets from disk with live template reload")
+	}
+
+	// Restore lobbies from the last snapshot, if any
+	lobbyStore := store.NewLobbyStore()
+	snapshotPath := cfg.Snapshot.File
+	if snapshotPath != "" {
+		n, err := lobbyStore.LoadSnapshot(snapshotPath, cfg.Game.MaxVoteRounds)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			slog.Info("No snapshot found, starting fresh", "path", snapshotPath)
+		case err != nil:
+			fatal("Failed to restore snapshot", "path", snapshotPath, "error", err)
+		default:
+			slog.Info("Restored lobbies from snapshot", "path", snapshotPath, "lobbies", n)
+		}
+	}
+
+	accountStore, err := store.NewAccountStore(cfg.Accounts.File)
+	if err != nil {
+		fatal("Failed to load accounts", "path", cfg.Accounts.File, "error", err)
+	}
+	if cfg.Accounts.File != "" {
+		slog.Info("Loaded accounts", "path", cfg.Accounts.File, "accounts", accountStore.Count())
 	}
 
 	// Initialize handler context
 	ctx := &handlers.Context{
-		LobbyStore: store.NewLobbyStore(),
+		LobbyStore: lobbyStore,
+		Accounts:   accountStore,
+		Scoring:    scoring.Default(),
+		Seeds:      game.NewSeedSource(int64(cfg.Game.Seed)),
 		Templates:  templates,
+		I18n:       catalog,
 		Locations:  locations,
 		Challenges: challenges,
-		BaseURL:    baseURL,
-	}
-
-	// Routes
-	http.HandleFunc("/", ctx.HandleIndex)
-	http.HandleFunc("/create", ctx.HandleCreateLobby)
-	http.HandleFunc("/join", ctx.HandleJoinLobby)
-	http.HandleFunc("/join/", ctx.HandleJoinMux) // Multiplexer for GET (join screen) and POST (join action)
-	http.HandleFunc("/lobby/", ctx.HandleLobby)
-	http.HandleFunc("/sse/", ctx.HandleSSE)
-	http.HandleFunc("/start-game/", ctx.HandleStartGame)
-	// Game multiplexer: phases (GET), actions (POST), and redirect helper
-	http.HandleFunc("/game/", ctx.HandleGameMux)
-	// Results
-	http.HandleFunc("/results/", ctx.HandleResults)
-	// Lobby/game lifecycle
-	http.HandleFunc("/restart-game/", ctx.HandleRestartGame)
-	http.HandleFunc("/close-lobby/", ctx.HandleCloseLobby)
-	http.HandleFunc("/leave-lobby/", ctx.HandleLeaveLobby)
-	http.HandleFunc("/select-host/", ctx.HandleSelectHost)
-	http.HandleFunc("/leave-lobby-with-host/", ctx.HandleLeaveLobbyWithHost)
+		Config:     cfg,
+	}
+
+	// Routes (each instrumented with per-route request metrics)
+	mux := http.NewServeMux()
+	ctx.Routes(mux)
 
 	// Static files
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
-	port := ":8080"
-	log.Printf("Server starting on %s", port)
-	log.Fatal(http.ListenAndServe(port, nil))
-}
+	// Prometheus scrape endpoint
+	metrics.RegisterStore(lobbyStore)
+	mux.Handle("GET /metrics", metrics.Handler())
 
-// loadData loads locations and challenges from JSON files
-func loadData() ([]models.Location, []string, error) {
-	// Load locations
-	var locations []models.Location
-	locationData, err := os.ReadFile("data/places.json")
-	if err != nil {
-		return nil, nil, fmt.Errorf("reading places.json: %w", err)
+	var handler http.Handler = mountAt(cfg.BasePath, ctx.Recover(mux))
+	if cfg.TLS.Enabled() || cfg.TLS.TrustProxy {
+		handler = secure.HSTS(handler, cfg.TLS.HSTSMaxAge, cfg.TLS.TrustProxy)
 	}
-	if err := json.Unmarshal(locationData, &locations); err != nil {
-		return nil, nil, fmt.Errorf("parsing places.json: %w", err)
+	srv := &http.Server{
+		Addr:    cfg.ListenAddr,
+		Handler: logging.Middleware(handler),
 	}
 
-	// Load challenges
-	var challenges []string
-	challengeData, err := os.ReadFile("data/challenges.json")
-	if err != nil {
-		return nil, nil, fmt.Errorf("reading challenges.json: %w", err)
+	// Plain HTTP listener that redirects to HTTPS (and answers ACME http-01 challenges)
+	var redirectSrv *http.Server
+	if cfg.TLS.RedirectAddr != "" {
+		redirectSrv = &http.Server{
+			Addr:    cfg.TLS.RedirectAddr,
+			Handler: secure.RedirectHandler(cfg.ListenAddr),
+		}
+	}
+
+	if cfg.TLS.ACMEEnabled() {
+		manager, err := secure.NewACMEManager(cfg.TLS)
+		if err != nil {
+			fatal("Invalid ACME configuration", "error", err)
+		}
+		srv.TLSConfig = manager.TLSConfig()
+		if redirectSrv != nil {
+			redirectSrv.Handler = manager.HTTPHandler(redirectSrv.Handler)
+		}
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("Server starting", "addr", cfg.ListenAddr, "tls", cfg.TLS.Enabled(), "acme", cfg.TLS.ACMEEnabled())
+		if cfg.TLS.Enabled() {
+			// With ACME the certificate comes from srv.TLSConfig and both paths are empty
+			serveErr <- srv.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+			return
+		}
+		serveErr <- srv.ListenAndServe()
+	}()
+	if redirectSrv != nil {
+		go func() {
+			slog.Info("HTTP redirect listener starting", "addr", redirectSrv.Addr)
+			serveErr <- redirectSrv.ListenAndServe()
+		}()
+	}
+
+	// Wait for SIGINT/SIGTERM (or the listener failing)
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var snapshotLoopDone <-chan struct{}
+	if snapshotPath != "" && cfg.Snapshot.Interval > 0 {
+		snapshotLoopDone = runSnapshotLoop(sigCtx, lobbyStore, snapshotPath, cfg.Snapshot.Interval)
+	}
+	select {
+	case err := <-serveErr:
+		fatal("Server failed", "error", err)
+	case <-sigCtx.Done():
+	}
+	stop()
+
+	slog.Info("Shutdown signal received, draining connections", "timeout", cfg.ShutdownTimeout.String())
+	ctx.BeginShutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Graceful shutdown incomplete", "error", err)
+	}
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(shutdownCtx)
+	}
+
+	// Saved once in-flight requests have finished, so what they changed is kept
+	if snapshotPath != "" {
+		// A periodic save still running writes the same temp file; let it finish
+		if snapshotLoopDone != nil {
+			<-snapshotLoopDone
+		}
+		if err := ctx.LobbyStore.SaveSnapshot(snapshotPath); err != nil {
+			slog.Error("Failed to write snapshot", "path", snapshotPath, "error", err)
+		} else {
+			slog.Info("Wrote snapshot", "path", snapshotPath, "lobbies", ctx.LobbyStore.Count())
+		}
 	}
-	if err := json.Unmarshal(challengeData, &challenges); err != nil {
-		return nil, nil, fmt.Errorf("parsing challenges.json: %w", err)
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server error", "error", err)
 	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
 
-	log.Printf("Loaded %d locations and %d challenges", len(locations), len(challenges))
+// mountAt serves h under prefix (e.g. "/games/sus") for reverse proxies that
+// forward the full path; the bare prefix redirects to its trailing-slash form
+func mountAt(prefix string, h http.Handler) http.Handler {
+	if prefix == "" {
+		return h
+	}
+	stripped := http.StripPrefix(prefix, h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix {
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	})
+}
+
+// runSnapshotLoop saves the store to path every interval until ctx is
+// cancelled. The returned channel is closed once the loop has stopped.
+func runSnapshotLoop(ctx context.Context, lobbyStore *store.LobbyStore, path string, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := lobbyStore.SaveSnapshot(path); err != nil {
+					slog.Error("Periodic snapshot failed", "path", path, "error", err)
+				} else {
+					slog.Debug("Periodic snapshot saved", "path", path, "lobbies", lobbyStore.Count())
+				}
+			}
+		}
+	}()
+	return done
+}
+
+// loadData loads locations and challenges for each language from JSON files in fsys.
+// English lives in places.json and challenges.json, other languages in
+// places.<lang>.json and challenges.<lang>.json; a language without its own
+// files falls back to English.
+func loadData(fsys fs.FS, languages []i18n.Language) (map[string][]models.Location, map[string][]string, error) {
+	locations := make(map[string][]models.Location)
+	challenges := make(map[string][]string)
+	for _, lang := range languages {
+		suffix := ".json"
+		if lang.Code != i18n.DefaultLanguage {
+			suffix = "." + lang.Code + ".json"
+		}
+
+		var langLocations []models.Location
+		found, err := readDataFile(fsys, "places"+suffix, &langLocations)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			locations[lang.Code] = langLocations
+		}
+
+		var langChallenges []string
+		found, err = readDataFile(fsys, "challenges"+suffix, &langChallenges)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			challenges[lang.Code] = langChallenges
+		}
+
+		slog.Info("Loaded game content", "language", lang.Code,
+			"locations", len(langLocations), "challenges", len(langChallenges))
+	}
+
+	if len(locations[i18n.DefaultLanguage]) == 0 || len(challenges[i18n.DefaultLanguage]) == 0 {
+		return nil, nil, fmt.Errorf("places.json and challenges.json must not be empty")
+	}
 	return locations, challenges, nil
 }
+
+// readDataFile decodes the JSON file name into v. Only the default language's
+// files are required; a missing localized file reports found=false.
+func readDataFile(fsys fs.FS, name string, v any) (found bool, err error) {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) && strings.Count(name, ".") > 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return true, nil
+}